@@ -0,0 +1,76 @@
+package sflag
+
+import "reflect"
+
+// Args renders the flag members of the struct pointed to by ss back into "--Name=value" tokens.
+// Parsing the result into a fresh struct of the same type reproduces *ss:
+// nil pointer members are left out, and the contents of the Args member follow a "--" terminator.
+// Members tagged [secret] are left out too, since a child's commandline shows in ps; pass them by --Name-file instead.
+func Args(ss interface{}) []string { return NamingVerbatim.Args(ss) }
+
+// Args is like the package function Args, but names the flags as naming does, e.g. p.Naming.Args(&opt) for a child
+// that parses with the same Parser settings.
+func (naming Naming) Args(ss interface{}) []string {
+	return renderArgs(reflect.ValueOf(ss).Elem(), naming, func(field, reflect.Value) bool { return true })
+}
+
+// ArgsNonDefault is like Args, but leaves out members whose value equals the default in their tag
+// (or the zero value, when the tag provides no default), since parsing would restore those anyway.
+func ArgsNonDefault(ss interface{}) []string { return NamingVerbatim.ArgsNonDefault(ss) }
+
+// ArgsNonDefault is like the package function ArgsNonDefault, but names the flags as naming does.
+func (naming Naming) ArgsNonDefault(ss interface{}) []string {
+	return renderArgs(reflect.ValueOf(ss).Elem(), naming, func(ff field, vv reflect.Value) bool {
+		if vv.Kind() == reflect.Ptr || !ff.hasDefault {
+			return !vv.IsZero()
+		}
+		return formatValue(vv) != formatValue(ff.defValue)
+	})
+}
+
+// Args is like Args, but renders only the flags that were set on the commandline in the parse that returned p, named as p.Naming names them.
+// With structs added by Register, it renders the flags of all of them, and the unconsumed flags once.  See View about Reload.
+func (p *Parser) Args() []string {
+	p.smu.RLock()
+	defer p.smu.RUnlock()
+	var args, rest []string
+	for _, root := range p.roots {
+		part := renderArgs(root, p.Naming, func(ff field, _ reflect.Value) bool {
+			return p.sources[ff.flagname] == SourceCommandLine
+		})
+		for ii, arg := range part {
+			if arg == "--" {
+				if rest == nil {
+					rest = part[ii:]
+				}
+				part = part[:ii]
+				break
+			}
+		}
+		args = append(args, part...)
+	}
+	return append(append([]string{}, args...), rest...)
+}
+
+func renderArgs(ssvalue reflect.Value, naming Naming, want func(field, reflect.Value) bool) []string {
+	if ssvalue.Kind() != reflect.Struct {
+		panic("sflag.Args was not provided a pointer to a struct")
+	}
+	args := []string{}
+	for _, ff := range planFor(ssvalue.Type(), naming).fields {
+		vv := lookup(ssvalue, ff.index)
+		if !vv.IsValid() || (vv.Kind() == reflect.Ptr && vv.IsNil()) {
+			continue
+		}
+		if !ff.supported || ff.secret() || !want(ff, vv) {
+			continue
+		}
+		args = append(args, "--"+ff.flagname+"="+escapeFileValue(formatValue(vv)))
+	}
+
+	if vv := ssvalue.FieldByName("Args"); vv.IsValid() && vv.Type().String() == "[]string" && vv.Len() > 0 {
+		args = append(args, "--")
+		args = append(args, vv.Interface().([]string)...)
+	}
+	return args
+}
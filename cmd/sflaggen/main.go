@@ -0,0 +1,496 @@
+// Command sflaggen generates reflection-free parsers for sflag options structs, for latency-sensitive CLIs and TinyGo builds.
+//
+// Given
+//
+//	//go:generate sflaggen --Type=Options
+//	type Options struct {
+//	    Usage    string "demonstrator"
+//	    SomeFile string "contains the something | /dev/null"
+//	    Args     []string
+//	}
+//
+// it writes options_sflag.go, declaring
+//
+//	func (opt *Options) ParseOptions(args []string) error
+//
+// which behaves like sflag.Parse(opt) with the Args member set to args: same tag syntax, defaults, flag names, Usage, Args and Set members,
+// nil pointer semantics, [default], [secret], [required], [min=N], [max=N], [enum=a/b], [count] and [hook=Method] options, --Foo-file and @file: values,
+// and the Validate and AfterParse methods of sflag.Validator and sflag.AfterParser.
+// It returns an error instead of panicking, and does not implement the standalone bool check of sflag.Parse2.
+// [hidden] members are left out of the Usage member, but there is no --help-all.
+// Only string, int, bool, int64 and float64 members and pointers to them are supported;
+// sflaggen fails on tagged members of the other types sflag.Parse binds (slices, durations, nested structs, ...).
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/LDCS/sflag"
+)
+
+var opt = struct {
+	Usage  string "generates reflection-free parsers for sflag options structs.  Remaining args are the files to search, default all of $GOFILE's package"
+	Type   string "name of the options struct type [required]"
+	Output string "file to write, default <type>_sflag.go next to the declaration | "
+	Args   []string
+}{}
+
+// member is a struct member that becomes a flag.
+type member struct {
+	name string // member name
+	kind string // string, int, bool, int64 or float64
+	ptr  bool
+	tag  sflag.Tag
+}
+
+// ptrDefault reports whether mm is a pointer member that gets its tag default, as with the [default] option.
+func (mm member) ptrDefault() bool {
+	_, ok := mm.tag.Options["default"]
+	return mm.ptr && ok && mm.tag.HasDefault
+}
+
+// typeString is the member type as sflag.Parse shows it in the Usage member, e.g. *int.
+func (mm member) typeString() string {
+	if mm.ptr {
+		return "*" + mm.kind
+	}
+	return mm.kind
+}
+
+func main() {
+	sflag.Parse(&opt)
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "sflaggen:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	files := opt.Args
+	if len(files) == 0 {
+		matches, _ := filepath.Glob("*.go")
+		for _, name := range matches {
+			if !strings.HasSuffix(name, "_test.go") && !strings.HasSuffix(name, "_sflag.go") {
+				files = append(files, name)
+			}
+		}
+	}
+
+	fset := token.NewFileSet()
+	for _, name := range files {
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			return err
+		}
+		st := findStruct(file, opt.Type)
+		if st == nil {
+			continue
+		}
+		members, usage, hasArgs, hasSet, err := members(st)
+		if err != nil {
+			return fmt.Errorf("%s: %v", fset.Position(st.Pos()), err)
+		}
+		src, err := generate(file.Name.Name, opt.Type, members, usage, hasArgs, hasSet)
+		if err != nil {
+			return fmt.Errorf("%s: %v", fset.Position(st.Pos()), err)
+		}
+		output := opt.Output
+		if output == "" {
+			output = filepath.Join(filepath.Dir(name), strings.ToLower(opt.Type)+"_sflag.go")
+		}
+		return os.WriteFile(output, src, 0644)
+	}
+	return fmt.Errorf("struct type %s not found in %s", opt.Type, strings.Join(files, " "))
+}
+
+// findStruct returns the declaration of struct type name in file, or nil.
+func findStruct(file *ast.File, name string) *ast.StructType {
+	var st *ast.StructType
+	ast.Inspect(file, func(node ast.Node) bool {
+		if ts, ok := node.(*ast.TypeSpec); ok && ts.Name.Name == name {
+			st, _ = ts.Type.(*ast.StructType)
+		}
+		return st == nil
+	})
+	return st
+}
+
+// members applies the rules sflag.Parse applies to reflected members to the declaration st.
+func members(st *ast.StructType) (members []member, usage *string, hasArgs, hasSet bool, err error) {
+	for _, fld := range st.Fields.List {
+		tag := ""
+		if fld.Tag != nil {
+			tag, _ = strconv.Unquote(fld.Tag.Value)
+		}
+		if len(fld.Names) == 0 {
+			continue // Skip embedded fields
+		}
+		kind, ptr := typeName(fld.Type)
+		for _, ident := range fld.Names {
+			switch {
+			case ident.Name == "Usage":
+				usage = &tag
+				continue // Not a flag
+			case kind == "[]string" && ident.Name == "Args":
+				hasArgs = true
+				continue // Already handled Args
+			case ident.Name == "Set" && types.ExprString(fld.Type) == "map[string]bool":
+				hasSet = true
+				continue // Filled after parsing
+			}
+			tt, ok := sflag.ParseTag(ident.Name, tag)
+			if !ok || !ident.IsExported() {
+				continue
+			}
+			switch kind {
+			case "string", "int", "bool", "int64", "float64":
+				members = append(members, member{name: ident.Name, kind: kind, ptr: ptr, tag: tt})
+			default:
+				return nil, nil, false, false, fmt.Errorf("member %s: type %s is not supported by sflaggen, use sflag.Parse", ident.Name, types.ExprString(fld.Type))
+			}
+		}
+	}
+	return members, usage, hasArgs, hasSet, nil
+}
+
+// typeName returns the name of a predeclared member type, and whether it is a pointer to that type.
+func typeName(expr ast.Expr) (string, bool) {
+	switch tt := expr.(type) {
+	case *ast.Ident:
+		return tt.Name, false
+	case *ast.StarExpr:
+		if id, ok := tt.X.(*ast.Ident); ok {
+			return id.Name, true
+		}
+	case *ast.ArrayType:
+		if id, ok := tt.Elt.(*ast.Ident); ok && tt.Len == nil {
+			return "[]" + id.Name, false
+		}
+	}
+	return "", false
+}
+
+// generate returns the formatted source of the ParseType method.
+func generate(pkg, typ string, members []member, usage *string, hasArgs, hasSet bool) ([]byte, error) {
+	var body bytes.Buffer
+	pf := func(format string, args ...interface{}) { fmt.Fprintf(&body, format, args...) }
+
+	moreusage := ""
+	hasSecret, hasRequired, hasUnsetHook := false, false, false
+	for _, mm := range members {
+		_, secret := mm.tag.Options["secret"]
+		_, required := mm.tag.Options["required"]
+		_, hook := mm.tag.Options["hook"]
+		hasSecret, hasRequired = hasSecret || secret, hasRequired || required
+		hasUnsetHook = hasUnsetHook || (hook && !mm.ptr && !mm.tag.HasDefault) // runs only if the flag was given
+		if _, hidden := mm.tag.Options["hidden"]; mm.tag.HasDefault && (!mm.ptr || mm.ptrDefault()) && !hidden {
+			shown := mm.tag.Default
+			if secret {
+				shown = "<redacted>"
+			}
+			moreusage += "\n\t--" + mm.tag.Flag + ": " + shown + " <-- Default, " + mm.typeString() + " # " + mm.tag.Description
+		}
+	}
+	if usage != nil {
+		pf("opt.Usage = \"\\n Usage of \" + os.Args[0] + \" # \" + %q + \"\\n ARGS:\" + %q\n\n", *usage, moreusage)
+	}
+
+	for _, mm := range members {
+		_, secret := mm.tag.Options["secret"]
+		fail := func(err string) string {
+			if secret {
+				return "hide(" + strconv.Quote(mm.tag.Flag) + ", " + err + ")"
+			}
+			return err
+		}
+		usage := " <--default, " + mm.typeString() + " # " + mm.tag.Description
+		if mm.ptr {
+			if !mm.ptrDefault() {
+				usage = ""
+			}
+			pf("if opt.%s == nil { // Ignore non-nil pointer members\n", mm.name)
+			if mm.ptrDefault() {
+				lit, err := literal(mm.kind, mm.tag.Default)
+				if err != nil {
+					return nil, err
+				}
+				pf("def := %s(%s)\nopt.%s = &def\n", mm.kind, lit, mm.name)
+			}
+		} else if mm.tag.HasDefault {
+			lit, err := literal(mm.kind, mm.tag.Default)
+			if err != nil {
+				return nil, err
+			}
+			pf("opt.%s = %s\n", mm.name, lit)
+		}
+
+		if _, ok := mm.tag.Options["alias"]; ok {
+			return nil, fmt.Errorf("option alias of %s is not supported by sflaggen, use sflag.Parse", mm.name)
+		}
+		_, counter := mm.tag.Options["count"]
+		if counter && mm.kind != "int" && mm.kind != "int64" {
+			return nil, fmt.Errorf("[count] needs an integer member, not %s", mm.name)
+		}
+		register := "Func"
+		if mm.kind == "bool" || counter {
+			register = "BoolFunc"
+		}
+		pf("flags.%s(%q, %q, func(s string) error {\n", register, mm.tag.Flag, usage)
+		pf("s, err := value(s)\nif err != nil {\nreturn err\n}\n")
+		if counter { // bare -v, which the flag package passes as for bool flags
+			current := "int64(opt." + mm.name + ")"
+			if mm.ptr {
+				pf("current := int64(0)\nif opt.%s != nil {\ncurrent = int64(*opt.%s)\n}\n", mm.name, mm.name)
+				current = "current"
+			}
+			pf("switch s {\ncase \"true\":\ns = strconv.FormatInt(%s+1, 10)\ncase \"false\":\ns = \"0\"\n}\n", current)
+		}
+		switch mm.kind {
+		case "string":
+			pf("vv := s\n")
+		case "int":
+			pf("num, err := strconv.ParseInt(s, 0, strconv.IntSize)\nif err != nil {\nreturn %s\n}\nvv := int(num)\n", fail("numErr(err)"))
+		case "int64":
+			pf("vv, err := strconv.ParseInt(s, 0, 64)\nif err != nil {\nreturn %s\n}\n", fail("numErr(err)"))
+		case "float64":
+			pf("vv, err := strconv.ParseFloat(s, 64)\nif err != nil {\nreturn %s\n}\n", fail("numErr(err)"))
+		case "bool":
+			pf("vv, err := strconv.ParseBool(s)\nif err != nil {\nreturn %s\n}\n", fail("errParse"))
+		}
+		if mm.ptr {
+			pf("opt.%s = &vv\n", mm.name)
+		} else {
+			pf("opt.%s = vv\n", mm.name)
+		}
+		pf("return nil\n})\n")
+
+		switch {
+		case secret:
+			pf("flags.Lookup(%q).DefValue = \"<redacted>\"\n", mm.tag.Flag)
+		case mm.ptrDefault():
+			pf("flags.Lookup(%q).DefValue = fmt.Sprint(*opt.%s)\n", mm.tag.Flag, mm.name)
+		case mm.ptr:
+			zero := map[string]string{"string": "", "bool": "false"}[mm.kind]
+			if zero == "" && mm.kind != "string" {
+				zero = "0"
+			}
+			pf("flags.Lookup(%q).DefValue = %q\n", mm.tag.Flag, zero)
+		default:
+			pf("flags.Lookup(%q).DefValue = fmt.Sprint(opt.%s)\n", mm.tag.Flag, mm.name)
+		}
+		pf("fileFlag(%q)\n", mm.tag.Flag)
+		if mm.ptr {
+			pf("}\n")
+		}
+		pf("\n")
+	}
+
+	pf("if err := flags.Parse(args); err != nil {\nreturn err\n}\n")
+	if hasSecret {
+		pf("if secretErr != nil {\nreturn secretErr\n}\n")
+	}
+	if hasArgs {
+		pf("opt.Args = append([]string{}, flags.Args()...)\n")
+	}
+	if hasRequired || hasSet || hasUnsetHook {
+		pf("set := map[string]bool{}\nflags.Visit(func(_flag *flag.Flag) { set[_flag.Name] = true })\n")
+	}
+	if hasSet {
+		pf("opt.Set = map[string]bool{}\n")
+		for _, mm := range members {
+			pf("if set[%[1]q] {\nopt.Set[%[1]q] = true\n}\n", mm.tag.Flag)
+		}
+	}
+	for _, mm := range members {
+		if err := checks(&body, mm); err != nil {
+			return nil, err
+		}
+	}
+	pf("if vv, ok := interface{}(opt).(interface{ Validate() error }); ok {\nif err := vv.Validate(); err != nil {\nreturn err\n}\n}\n")
+	for _, mm := range members {
+		method, ok := mm.tag.Options["hook"]
+		switch {
+		case !ok:
+			continue
+		case mm.ptr:
+			pf("if opt.%s != nil {\n", mm.name)
+		case !mm.tag.HasDefault:
+			pf("if set[%q] {\n", mm.tag.Flag)
+		default:
+			pf("{\n")
+		}
+		pf("if err := opt.%s(); err != nil {\nreturn fmt.Errorf(%q, err)\n}\n}\n", method, "flag -"+mm.tag.Flag+": %v")
+	}
+	pf("if vv, ok := interface{}(opt).(interface{ AfterParse() error }); ok {\nreturn vv.AfterParse()\n}\n")
+	pf("return nil\n")
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by sflaggen --Type=%s; DO NOT EDIT.\n\npackage %s\n\nimport (\n", typ, pkg)
+	for _, imp := range []string{"errors", "flag", "fmt", "math", "os", "strconv", "strings"} {
+		if (imp != "fmt" && imp != "math") || bytes.Contains(body.Bytes(), []byte(imp+".")) || (imp == "fmt" && hasSecret) {
+			fmt.Fprintf(&src, "%q\n", imp)
+		}
+	}
+	fmt.Fprintf(&src, ")\n\n// Parse%[1]s is the reflection-free equivalent of sflag.Parse(opt) with the Args member set to args.\n", typ)
+	fmt.Fprintf(&src, "// It returns parse errors instead of panicking.\nfunc (opt *%[1]s) Parse%[1]s(args []string) error {\n", typ)
+	src.WriteString(prelude)
+	if hasSecret {
+		src.WriteString(secretPrelude)
+	}
+	src.Write(body.Bytes())
+	src.WriteString("}\n")
+	return format.Source(src.Bytes())
+}
+
+// prelude declares the helpers used by every generated parser, as closures so that several can share a package.
+const prelude = `flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+errParse, errRange := errors.New("parse error"), errors.New("value out of range") // as reported by the flag package
+numErr := func(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+		return errRange
+	}
+	return errParse
+}
+readFile := func(path string) (string, error) {
+	buf, err := os.ReadFile(path)
+	return strings.TrimRight(string(buf), "\r\n"), err
+}
+escaped := func(s string) bool { return strings.HasPrefix(s, "@@") && strings.HasPrefix(strings.TrimLeft(s, "@"), "file:") }
+value := func(s string) (string, error) {
+	if path := strings.TrimPrefix(s, "@file:"); path != s {
+		return readFile(path)
+	}
+	if escaped(s) {
+		return s[1:], nil // @@file:x is the value @file:x
+	}
+	return s, nil
+}
+fileFlag := func(name string) {
+	flags.Func(name+"-file", " <--file to read the value of --"+name+" from", func(path string) error {
+		content, err := readFile(path)
+		if err != nil {
+			return err
+		}
+		if escaped(content) || strings.HasPrefix(content, "@file:") {
+			content = "@" + content
+		}
+		return flags.Set(name, content)
+	})
+}
+_, _, _ = numErr, value, fileFlag // unused if the struct has no members of some kinds
+
+`
+
+// secretPrelude keeps the values of [secret] members out of the errors reported by the flag package.
+const secretPrelude = `var secretErr error
+hide := func(name string, err error) error {
+	if secretErr == nil {
+		secretErr = fmt.Errorf("invalid value <redacted> for flag -%s: %v", name, err)
+	}
+	return nil
+}
+_ = hide // unused if all [secret] members are strings
+
+`
+
+// checks writes the post-parse validation of the required, min, max and enum options of mm.
+func checks(body *bytes.Buffer, mm member) error {
+	pf := func(format string, args ...interface{}) { fmt.Fprintf(body, format, args...) }
+	ref, shown := "opt."+mm.name, "opt."+mm.name
+	if mm.ptr {
+		ref, shown = "*opt."+mm.name, "*opt."+mm.name
+	}
+	if _, ok := mm.tag.Options["secret"]; ok {
+		shown = `"<redacted>"`
+	}
+
+	if _, ok := mm.tag.Options["required"]; ok {
+		pf("if !set[%q] {\nreturn errors.New(%q)\n}\n", mm.tag.Flag, "flag -"+mm.tag.Flag+" is required")
+	}
+	var conds []string
+	for _, bound := range []string{"min", "max"} {
+		limit, ok := mm.tag.Options[bound]
+		if !ok {
+			continue
+		}
+		lnum, err := strconv.ParseFloat(limit, 64)
+		if err != nil {
+			return fmt.Errorf("bad %s=%s option on member %s", bound, limit, mm.name)
+		}
+		if mm.kind != "int" && mm.kind != "int64" && mm.kind != "float64" {
+			return fmt.Errorf("%s option on non-numeric member %s", bound, mm.name)
+		}
+		op := "<"
+		if bound == "max" {
+			op = ">"
+		}
+		conds = append(conds, fmt.Sprintf("if float64(%s) %s %s {\nreturn fmt.Errorf(\"invalid value %%v for flag -%s: %s is %s\", %s)\n}\n",
+			ref, op, floatLiteral(lnum), mm.tag.Flag, bound, limit, shown))
+	}
+	if list, ok := mm.tag.Options["enum"]; ok {
+		var choices, tests []string
+		for _, choice := range strings.Split(list, "/") {
+			choice = strings.TrimSpace(choice)
+			lit, err := literal(mm.kind, choice)
+			if err != nil {
+				return err
+			}
+			choices = append(choices, choice)
+			tests = append(tests, ref+" != "+lit)
+		}
+		conds = append(conds, fmt.Sprintf("if %s {\nreturn fmt.Errorf(\"invalid value %%v for flag -%s: must be one of %s\", %s)\n}\n",
+			strings.Join(tests, " && "), mm.tag.Flag, strings.Join(choices, ", "), shown))
+	}
+	if len(conds) == 0 {
+		return nil
+	}
+	if mm.ptr {
+		pf("if opt.%s != nil {\n%s}\n", mm.name, strings.Join(conds, ""))
+	} else {
+		pf("%s", strings.Join(conds, ""))
+	}
+	return nil
+}
+
+// literal returns the Go literal of the value sflag.Parse derives from the tag default def.
+func literal(kind, def string) (string, error) {
+	switch kind {
+	case "string":
+		return strconv.Quote(def), nil
+	case "int", "int64":
+		inum, _ := strconv.ParseInt(def, 10, 64)
+		return strconv.FormatInt(inum, 10), nil
+	case "bool":
+		bnum, _ := strconv.ParseBool(def)
+		return strconv.FormatBool(bnum), nil
+	case "float64":
+		fnum, _ := strconv.ParseFloat(def, 64)
+		return floatLiteral(fnum), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", kind)
+}
+
+func floatLiteral(fnum float64) string {
+	switch {
+	case math.IsNaN(fnum):
+		return "math.NaN()"
+	case math.IsInf(fnum, 0):
+		return fmt.Sprintf("math.Inf(%d)", int(math.Copysign(1, fnum)))
+	}
+	lit := strconv.FormatFloat(fnum, 'g', -1, 64)
+	if !strings.ContainsAny(lit, ".e") {
+		lit += ".0"
+	}
+	return lit
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const src = `package demo
+
+type Options struct {
+	Usage    string  "demonstrator"
+	SomeFile string  "contains the something [hook=Check] | /dev/null"
+	GDP      float64 "in Dong [min=0]           | 4.2e25"
+	Verbose  bool    "chatty                   | false"
+	Pin      int     "pin [secret,required]"
+	Bar      *int    "bar"
+	Def      *int    "def [default]            | 7"
+	Loud     int     "verbosity [count]        | 0"
+	Baz_     int     "Set by --baz, not --Baz  | 42"
+	Args     []string
+	Set      map[string]bool
+	ignored  string
+}
+`
+
+// TestGenerate checks the generated source is valid Go with the expected flags, defaults and checks
+func TestGenerate(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "demo.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	members, usage, hasArgs, hasSet, err := members(findStruct(file, "Options"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 8 || usage == nil || *usage != "demonstrator" || !hasArgs || !hasSet {
+		t.Fatalf("members %+v, usage %v, hasArgs %v, hasSet %v", members, usage, hasArgs, hasSet)
+	}
+
+	out, err := generate("demo", "Options", members, usage, hasArgs, hasSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	demo, err := parser.ParseFile(fset, "demo.go", src+"func (opt *Options) Check() error { return nil }\n", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen, err := parser.ParseFile(fset, "options_sflag.go", out, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("demo", fset, []*ast.File{demo, gen}, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"package demo\n",
+		"func (opt *Options) ParseOptions(args []string) error {",
+		`opt.SomeFile = "/dev/null"`,
+		"opt.GDP = 4.2e+25",
+		`flags.BoolFunc("Verbose", " <--default, bool # chatty"`,
+		`return hide("Pin", numErr(err))`,
+		`flags.Lookup("Pin").DefValue = "<redacted>"`,
+		"if opt.Bar == nil {",
+		"opt.Bar = &vv",
+		"def := int(7)",
+		`flags.Lookup("Def").DefValue = fmt.Sprint(*opt.Def)`,
+		`flags.Func("baz",`,
+		`if !set["Pin"] {`,
+		"if float64(opt.GDP) < 0.0 {",
+		`fileFlag("SomeFile")`,
+		"opt.Args = append([]string{}, flags.Args()...)",
+		`opt.Set["baz"] = true`,
+		`flags.BoolFunc("Loud",`,
+		"s = strconv.FormatInt(int64(opt.Loud)+1, 10)",
+		"if err := opt.Check(); err != nil {",
+		"if vv, ok := interface{}(opt).(interface{ Validate() error }); ok {",
+		"return vv.AfterParse()",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("generated source lacks %q", want)
+		}
+	}
+	if strings.Contains(string(out), "ignored") {
+		t.Error("generated source binds members sflag ignores")
+	}
+}
+
+// TestUnsupported checks that members sflaggen cannot generate code for are reported, rather than left unset
+func TestUnsupported(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "demo.go", "package demo\ntype Options struct {\n\tPeers []string \"other servers | a,b\"\n}\n", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, _, err := members(findStruct(file, "Options")); err == nil || !strings.Contains(err.Error(), "member Peers: type []string is not supported") {
+		t.Errorf("got error %v", err)
+	}
+}
+
+// driver parses each argument vector with the generated parser and with sflag.Parse, printing both results
+const driver = `
+func (opt *Options) Check() error { return nil }
+
+func show(opt *Options, err interface{}) string {
+	if err != nil {
+		return fmt.Sprint("error: ", err != nil)
+	}
+	bar, def := "nil", "nil"
+	if opt.Bar != nil {
+		bar = fmt.Sprint(*opt.Bar)
+	}
+	if opt.Def != nil {
+		def = fmt.Sprint(*opt.Def)
+	}
+	opt.Bar, opt.Def = nil, nil
+	return fmt.Sprintf("%#v Bar:%s Def:%s", *opt, bar, def)
+}
+
+func main() {
+	for _, args := range [][]string{
+		{"--Pin=1"},
+		{"--Pin=0x10", "--Verbose", "--Def=3", "--Bar=010", "--Loud", "--Loud", "--GDP=1e3", "a", "b"},
+		{"--baz=010", "--Pin", "2", "--SomeFile=@file:/dev/null"},
+		{"--SomeFile=@@file:x", "--Pin=1"},
+		{"--Pin=1", "--GDP=-1"},
+		{"--Verbose"},
+		{"--Pin=x"},
+	} {
+		var gen Options
+		err := gen.ParseOptions(append([]string{}, args...))
+		fmt.Println("generated:", show(&gen, err))
+
+		lib := Options{Args: append([]string{}, args...)}
+		fmt.Println("sflag:    ", show(&lib, func() (err interface{}) {
+			defer func() { err = recover() }()
+			(&sflag.Parser{FlagSet: flag.NewFlagSet(os.Args[0], flag.PanicOnError)}).Parse(&lib)
+			return nil
+		}()))
+	}
+}
+`
+
+// TestBehavior checks that the generated parser sets the members like sflag.Parse does, running both on the same arguments
+func TestBehavior(t *testing.T) {
+	gotool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip(err)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), "demo.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	members, usage, hasArgs, hasSet, err := members(findStruct(file, "Options"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := generate("main", "Options", members, usage, hasArgs, hasSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	gomod := "module behavior\n\ngo 1.22\n\nrequire github.com/LDCS/sflag v0.0.0\n\nreplace github.com/LDCS/sflag => " + root + "\n"
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0600)
+	prog := strings.Replace(src, "package demo\n", "package main\n\nimport (\n\t\"flag\"\n\t\"fmt\"\n\t\"os\"\n\n\t\"github.com/LDCS/sflag\"\n)\n", 1) + driver
+	os.WriteFile(filepath.Join(dir, "demo.go"), []byte(prog), 0600)
+	os.WriteFile(filepath.Join(dir, "options_sflag.go"), out, 0600)
+	cmd := exec.Command(gotool, "run", "demo.go", "options_sflag.go")
+	cmd.Dir = dir
+	result, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("%v\n%s", err, result)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(result)), "\n")
+	if len(lines) != 14 {
+		t.Fatalf("got %s", result)
+	}
+	for ii := 0; ii < len(lines); ii += 2 {
+		gen, lib := strings.TrimPrefix(lines[ii], "generated:"), strings.TrimPrefix(lines[ii+1], "sflag:    ")
+		if gen != lib {
+			t.Errorf("generated parser and sflag.Parse differ:\n%s\n%s", lines[ii], lines[ii+1])
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package sflag
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Format selects the layout written by Encode.
+type Format int
+
+const (
+	FormatKeyValue Format = iota // one Name=value line per flag, values with control characters quoted like Go strings
+	FormatEnv                    // one NAME=value line per flag, single-quoted where needed, for sourcing by sh
+	FormatJSON                   // a single JSON object keyed by flag name
+)
+
+// redacted replaces the value of members tagged [secret].
+const redacted = "<redacted>"
+
+// Encode writes the flag members of the struct pointed to by ss to w, e.g. to log the effective options at startup.
+// It walks the same members Parse does.  Members tagged [secret] are masked, nil pointer members are omitted (null in JSON).
+func Encode(w io.Writer, ss interface{}, format Format) error {
+	return NamingVerbatim.Encode(w, ss, format)
+}
+
+// Encode is like the package function Encode, but names the flags as naming does.
+func (naming Naming) Encode(w io.Writer, ss interface{}, format Format) error {
+	ssvalue := reflect.ValueOf(ss)
+	if ssvalue.Kind() != reflect.Ptr || ssvalue.Elem().Kind() != reflect.Struct {
+		panic("sflag.Encode was not provided a pointer to a struct")
+	}
+	ssvalue = ssvalue.Elem()
+
+	bw := bufio.NewWriter(w)
+	if format == FormatJSON {
+		bw.WriteString("{")
+	}
+	sep := "\n"
+	for _, ff := range planFor(ssvalue.Type(), naming).fields {
+		if !ff.supported {
+			continue
+		}
+		vv := lookup(ssvalue, ff.index)
+		secret := ff.secret()
+		isNil := !vv.IsValid() || (vv.Kind() == reflect.Ptr && vv.IsNil())
+
+		switch format {
+		case FormatKeyValue, FormatEnv:
+			if isNil {
+				continue
+			}
+			value := formatValue(vv)
+			if secret {
+				value = redacted
+			}
+			if format == FormatEnv {
+				fmt.Fprintf(bw, "%s=%s\n", envName(ff.flagname), envQuote(value))
+			} else {
+				fmt.Fprintf(bw, "%s=%s\n", ff.flagname, logQuote(value))
+			}
+		case FormatJSON:
+			var value interface{}
+			switch {
+			case secret:
+				value = redacted
+			case !isNil:
+				value = jsonNative(vv)
+			}
+			buf, err := jsonValue(value)
+			if err != nil {
+				return fmt.Errorf("sflag: cannot encode --%s: %v", ff.flagname, err)
+			}
+			key, _ := jsonValue(ff.flagname)
+			fmt.Fprintf(bw, "%s  %s: %s", sep, key, buf)
+			sep = ",\n"
+		default:
+			return fmt.Errorf("sflag: unknown format %d", format)
+		}
+	}
+	if format == FormatJSON {
+		if sep == "\n" {
+			bw.WriteString("}\n")
+		} else {
+			bw.WriteString("\n}\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// jsonValue is json.Marshal without the HTML escaping, which would mangle the redacted marker.
+func jsonValue(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// jsonNative returns vv (dereferenced if it is a pointer) as a value that encodes to JSON the way it reads:
+// numbers and booleans as such, slices as arrays, and everything else, such as durations, in its flag syntax.
+func jsonNative(vv reflect.Value) interface{} {
+	if vv.Kind() == reflect.Ptr {
+		vv = vv.Elem()
+	}
+	typ := vv.Type()
+	if isList(typ) {
+		list := make([]interface{}, vv.Len())
+		for ii := range list {
+			list[ii] = jsonNative(vv.Index(ii))
+		}
+		return list
+	}
+	if isText(typ) || typ == durationType {
+		return formatValue(vv)
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return vv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return vv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return vv.Uint()
+	case reflect.Float32, reflect.Float64:
+		return json.Number(formatValue(vv)) // shortest form for the member's precision
+	}
+	return formatValue(vv)
+}
+
+// envName turns a flag name such as SomeFile into an environment variable name such as SOME_FILE.
+func envName(flagname string) string {
+	var buf strings.Builder
+	runes := []rune(flagname)
+	for ii, rr := range runes {
+		if ii > 0 && unicode.IsUpper(rr) && (unicode.IsLower(runes[ii-1]) || (ii+1 < len(runes) && unicode.IsLower(runes[ii+1]))) {
+			buf.WriteByte('_')
+		}
+		switch {
+		case unicode.IsLetter(rr) || unicode.IsDigit(rr):
+			buf.WriteRune(unicode.ToUpper(rr))
+		default:
+			buf.WriteByte('_')
+		}
+	}
+	return buf.String()
+}
+
+// envQuote single-quotes value, the way sh reads it back verbatim, unless it consists of characters sh takes literally.
+func envQuote(value string) string {
+	safe := value != "" && strings.IndexFunc(value, func(rr rune) bool {
+		return rr > unicode.MaxASCII || !(unicode.IsLetter(rr) || unicode.IsDigit(rr) || strings.ContainsRune("_-./:,+=@%", rr))
+	}) < 0
+	if safe {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// logQuote quotes value like a Go string if it holds characters that could fake or garble lines of the log,
+// such as newlines, or starts with a quote, which would make it look quoted.
+func logQuote(value string) string {
+	if strings.IndexFunc(value, func(rr rune) bool { return !unicode.IsPrint(rr) }) < 0 && !strings.HasPrefix(value, `"`) {
+		return value
+	}
+	return strconv.Quote(value)
+}
@@ -0,0 +1,73 @@
+package sflag
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// enumValue is one of the values registered for a type with RegisterEnum.
+type enumValue struct {
+	name  string
+	value reflect.Value
+}
+
+var enums sync.Map // reflect.Type -> []enumValue
+
+// RegisterEnum declares the values of a named type, given as a slice of that type, e.g. sflag.RegisterEnum([]Mode{Fast, Slow}).
+// Members of the type (and slices of it) then take the names of those values, ignoring case, and reject other values with a suggestion.
+// Values are named by their String method if they have one, else by their value.  The empty string sets the zero value.
+// Usage and Schema list the names.  Register before parsing, e.g. in an init func.
+func RegisterEnum(values interface{}) {
+	vv := reflect.ValueOf(values)
+	if vv.Kind() != reflect.Slice || vv.Len() == 0 {
+		panic("sflag.RegisterEnum was not provided a non-empty slice")
+	}
+	list := make([]enumValue, vv.Len())
+	for ii := range list {
+		list[ii] = enumValue{name: fmt.Sprint(vv.Index(ii).Interface()), value: vv.Index(ii)}
+	}
+	enums.Store(vv.Type().Elem(), list)
+	plans.Range(func(sstype, _ interface{}) bool { // compiled usage lacks the names
+		plans.Delete(sstype)
+		return true
+	})
+}
+
+// enumValues returns the values registered for typ, or nil.
+func enumValues(typ reflect.Type) []enumValue {
+	if list, ok := enums.Load(typ); ok {
+		return list.([]enumValue)
+	}
+	return nil
+}
+
+// enumNames returns the names of the values registered for typ, or of its elements if typ is a slice, or nil.
+func enumNames(typ reflect.Type) []string {
+	if isList(typ) {
+		typ = typ.Elem()
+	}
+	var names []string
+	for _, ev := range enumValues(typ) {
+		names = append(names, ev.name)
+	}
+	return names
+}
+
+// setEnum sets vv to the value in list named s.
+func setEnum(vv reflect.Value, list []enumValue, s string) error {
+	if s == "" {
+		vv.Set(reflect.Zero(vv.Type()))
+		return nil
+	}
+	names := make([]string, len(list))
+	for ii, ev := range list {
+		if strings.EqualFold(ev.name, s) {
+			vv.Set(ev.value)
+			return nil
+		}
+		names[ii] = ev.name
+	}
+	return fmt.Errorf("must be one of %s%s", strings.Join(names, ", "), didYouMean(s, names))
+}
@@ -0,0 +1,3 @@
+module github.com/LDCS/sflag
+
+go 1.22
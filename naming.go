@@ -0,0 +1,73 @@
+package sflag
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Naming selects how Parse derives flag names from member names (see Parser.Naming).
+// The name option of a tag, e.g. "input file [name=in] | -", overrides it for that member.
+type Naming int
+
+const (
+	NamingVerbatim   Naming = iota // SomeFile gives --SomeFile, and Some_ gives --some
+	NamingLowerCamel               // SomeFile gives --someFile
+	NamingKebab                    // SomeFile gives --some-file
+	NamingSnake                    // SomeFile gives --some_file
+)
+
+// flagName returns the flag name of the member called name.  A trailing underscore, as in Foo_, is dropped, and NamingVerbatim then lowers the first letter.
+func (naming Naming) flagName(name string) string {
+	if naming == NamingVerbatim {
+		if nn := len(name) - 1; name[nn] == '_' { // User wants to look for --f* instead of --F*
+			return strings.ToLower(name[:1]) + name[1:nn]
+		}
+		return name
+	}
+
+	parts := words(strings.TrimSuffix(name, "_"))
+	switch naming {
+	case NamingLowerCamel:
+		parts[0] = strings.ToLower(parts[0])
+		return strings.Join(parts, "")
+	case NamingSnake:
+		return strings.ToLower(strings.Join(parts, "_"))
+	}
+	return strings.ToLower(strings.Join(parts, "-"))
+}
+
+// words splits a member name such as HTTPPortV2 at its case changes and underscores, into HTTP, Port and V2.
+func words(name string) []string {
+	var parts []string
+	runes := []rune(name)
+	start := 0
+	for ii, rr := range runes {
+		switch {
+		case rr == '_':
+			if ii > start {
+				parts = append(parts, string(runes[start:ii]))
+			}
+			start = ii + 1
+		case ii > start && unicode.IsUpper(rr) && (unicode.IsLower(runes[ii-1]) || unicode.IsDigit(runes[ii-1]) || (ii+1 < len(runes) && unicode.IsLower(runes[ii+1]))):
+			parts = append(parts, string(runes[start:ii]))
+			start = ii
+		}
+	}
+	if start < len(runes) {
+		parts = append(parts, string(runes[start:]))
+	}
+	if len(parts) == 0 {
+		parts = []string{name}
+	}
+	return parts
+}
+
+// foldName reduces a flag name to the form IgnoreCase compares: lower case, without dashes and underscores.
+func foldName(flagname string) string {
+	return strings.Map(func(rr rune) rune {
+		if rr == '-' || rr == '_' {
+			return -1
+		}
+		return unicode.ToLower(rr)
+	}, flagname)
+}
@@ -0,0 +1,100 @@
+package sflag
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var errEcho = errors.New("the answer of a [secret] member would show")
+
+// prompt asks for the values of the [required] members that were not given, re-asking until an answer parses.
+// It fails if there is no terminal to ask on, or the answers run out.
+func (p *Parser) prompt() error {
+	var missing []string
+	for _, flagname := range p.order {
+		if _, ok := p.fields[flagname].opts["required"]; ok && !p.IsSet(flagname) {
+			missing = append(missing, flagname)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	in, out, tty := p.PromptIn, p.PromptOut, false
+	if in == nil {
+		if !isTerminal(os.Stdin.Fd()) {
+			return fmt.Errorf("flag -%s is required (cannot prompt, stdin is not a terminal)", missing[0])
+		}
+		in, tty = os.Stdin, true
+	}
+	if out == nil {
+		out = os.Stderr
+	}
+	reader := bufio.NewReader(in)
+
+	for _, flagname := range missing {
+		ff := p.fields[flagname]
+		question := flagname
+		if desc := ff.description(); desc != "" {
+			question += " (" + desc + ")"
+		}
+		if ff.hasDefault && ff.def != "" && !ff.secret() {
+			question += " [" + ff.def + "]"
+		}
+		for {
+			fmt.Fprint(out, question+": ")
+			answer, err := readAnswer(reader, tty && ff.secret())
+			if ff.secret() {
+				fmt.Fprintln(out) // the newline was not echoed
+			}
+			if err == errEcho {
+				return fmt.Errorf("flag -%s is required (cannot prompt, %v)", flagname, err)
+			}
+			if err != nil {
+				return fmt.Errorf("flag -%s is required (no answer: %v)", flagname, err)
+			}
+			if answer == "" && ff.hasDefault {
+				answer = ff.def
+			}
+			if answer == "" {
+				fmt.Fprintln(out, "a value is required")
+				continue
+			}
+			if err := p.flags.Set(flagname, answer); err != nil {
+				fmt.Fprintf(out, "invalid value %q for flag -%s: %v\n", answer, flagname, err)
+				continue
+			}
+			if p.err != nil { // of a [secret] member, which does not show the value
+				fmt.Fprintln(out, p.err)
+				p.err = nil
+				continue
+			}
+			p.visited[flagname] = true
+			p.sources[flagname] = SourcePrompt
+			p.argv = append([]string{"--" + flagname + "=" + answer}, p.argv...) // for Reload
+			break
+		}
+	}
+	return nil
+}
+
+// readAnswer reads a line from reader, without the line ending.  With noEcho, the terminal on stdin does not show the typed text,
+// and readAnswer fails with errEcho rather than show it if the echo cannot be turned off.
+func readAnswer(reader *bufio.Reader, noEcho bool) (string, error) {
+	if noEcho {
+		restore, err := hideInput(os.Stdin.Fd())
+		if err != nil {
+			return "", errEcho
+		}
+		defer restore()
+	}
+	line, err := reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
@@ -0,0 +1,173 @@
+package sflag
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// change is a [reloadable] member that Reload updated.
+type change struct {
+	flagname string
+	old, new interface{}
+}
+
+// Reload parses the arguments of the last parse of p again, into fresh copies of its structs, so that values read from
+// files (--Name-file and @file:) are read anew.  If that parse succeeds, including the checks of tag options, Reload
+// copies the members tagged [reloadable] whose value changed into the parsed structs, and then runs the hook and calls p.OnReload for each.
+// Other members keep their values, and AfterParse is not called again.  Reload returns the error of the parse, leaving all members alone, or of the first hook that failed.
+// Members are updated while p is locked, so readers that access them inside View see either all old or all new values.
+func (p *Parser) Reload() error {
+	if p.roots == nil {
+		return errors.New("sflag: Reload before Parse")
+	}
+	q := &Parser{Strict: p.Strict, Warn: func(string) {}, All: p.All, Naming: p.Naming, IgnoreCase: p.IgnoreCase, reloading: true} // the parse warned already
+	q.FlagSet = flag.NewFlagSet(p.flags.Name(), flag.ContinueOnError)
+	q.FlagSet.SetOutput(io.Discard)
+	if p.FlagSet != nil {
+		p.FlagSet.VisitAll(func(_flag *flag.Flag) { // defined by others, whose variables Reload leaves alone
+			if _, ok := p.owners[_flag.Name]; !ok && _flag.Name != "help-all" {
+				q.FlagSet.Var(ignoredValue{_flag.Value}, _flag.Name, _flag.Usage)
+			}
+		})
+	}
+
+	fresh := make([]interface{}, len(p.roots))
+	for ii, root := range p.roots {
+		fresh[ii] = reflect.New(root.Type()).Interface()
+	}
+	q.registered, q.argv = fresh[1:], p.argv
+	if err := q.tryParse(fresh[0]); err != nil {
+		return fmt.Errorf("sflag: reload: %v", err)
+	}
+
+	var changes []change
+	p.mu.Lock()
+	for _, flagname := range p.order {
+		ff := p.fields[flagname]
+		if !ff.reloadable() {
+			continue
+		}
+		newvv := q.target(q.fields[flagname], false)
+		if !newvv.IsValid() {
+			continue // below a nil nested struct
+		}
+		oldvv := p.target(ff, true)
+		if formatValue(oldvv) == formatValue(newvv) {
+			continue
+		}
+		changes = append(changes, change{flagname, oldvv.Interface(), newvv.Interface()})
+		oldvv.Set(newvv)
+		p.smu.Lock()
+		p.sources[flagname] = q.sources[flagname]
+		p.smu.Unlock()
+	}
+	p.files = q.files
+	p.mu.Unlock()
+
+	var err error
+	for _, cc := range changes {
+		if herr := p.hook(p.fields[cc.flagname]); herr != nil && err == nil {
+			err = herr
+		}
+		if p.OnReload != nil {
+			p.OnReload(cc.flagname, cc.old, cc.new)
+		}
+	}
+	return err
+}
+
+// tryParse is parseInternal returning its panic as an error.
+func (p *Parser) tryParse(ss interface{}) (err error) {
+	defer func() {
+		if rr := recover(); rr != nil {
+			err = fmt.Errorf("%v", rr)
+		}
+	}()
+	p.parseInternal(ss, true)
+	return nil
+}
+
+// View calls fn while p is locked against Reload, so that fn sees the [reloadable] members of a single parse.
+// While Reload may run (see Watch), call Dump and Args inside View too, so that they show the values of a single parse.
+func (p *Parser) View(fn func()) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	fn()
+}
+
+// Watch calls Reload whenever one of signals arrives, e.g. syscall.SIGHUP, and, if interval is positive, whenever
+// one of the files that values were read from changes, checking every interval.  Reload errors are passed to warn (see Parser.Warn).
+// Call the returned func to stop watching.
+func (p *Parser) Watch(interval time.Duration, signals ...os.Signal) (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	if len(signals) > 0 {
+		signal.Notify(sigs, signals...)
+	}
+	var tick <-chan time.Time
+	var ticker *time.Ticker
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+		tick = ticker.C
+	}
+
+	done := make(chan struct{})
+	stamps := p.fileStamps()
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-sigs:
+			case <-tick:
+				if reflect.DeepEqual(stamps, p.fileStamps()) {
+					continue
+				}
+			}
+			if err := p.Reload(); err != nil {
+				p.warn(err.Error())
+			}
+			stamps = p.fileStamps()
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			if ticker != nil {
+				ticker.Stop()
+			}
+			close(done)
+		})
+	}
+}
+
+// fileStamps returns the modification times of the files that values were read from, by path.
+func (p *Parser) fileStamps() map[string]time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	stamps := make(map[string]time.Time, len(p.files))
+	for path := range p.files {
+		if info, err := os.Stat(path); err == nil {
+			stamps[path] = info.ModTime()
+		}
+	}
+	return stamps
+}
+
+// ignoredValue stands in for a flag defined by others while Reload parses, accepting its values without setting them.
+type ignoredValue struct{ flag.Value }
+
+func (iv ignoredValue) Set(string) error { return nil }
+
+func (iv ignoredValue) IsBoolFlag() bool {
+	bf, ok := iv.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
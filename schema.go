@@ -0,0 +1,170 @@
+package sflag
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+)
+
+// schemaProperty is the JSON Schema of a single flag.
+type schemaProperty struct {
+	Type        string            `json:"type"`
+	Items       *schemaProperty   `json:"items,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Default     json.RawMessage   `json:"default,omitempty"`
+	Enum        []json.RawMessage `json:"enum,omitempty"`
+	Minimum     json.RawMessage   `json:"minimum,omitempty"`
+	Maximum     json.RawMessage   `json:"maximum,omitempty"`
+	WriteOnly   bool              `json:"writeOnly,omitempty"`
+}
+
+// schemaProperties keeps the flags in struct order when marshalled.
+type schemaProperties struct {
+	names []string
+	props map[string]schemaProperty
+}
+
+func (sp schemaProperties) MarshalJSON() ([]byte, error) {
+	buf := []byte{'{'}
+	for ii, name := range sp.names {
+		if ii > 0 {
+			buf = append(buf, ',')
+		}
+		key, _ := json.Marshal(name)
+		prop, err := json.Marshal(sp.props[name])
+		if err != nil {
+			return nil, err
+		}
+		buf = append(append(append(buf, key...), ':'), prop...)
+	}
+	return append(buf, '}'), nil
+}
+
+// Schema returns a JSON Schema document describing the flags of the struct pointed to by ss, so that
+// external tools can validate a configuration before launching.  Properties are named after the flags,
+// tag descriptions and defaults become description and default, and the required, min, max and enum
+// options become the matching schema keywords.  [hidden] members are left out, as are defaults of [secret] members.  Members with units,
+// such as ByteSize and time.Duration, are strings in the schema, so their min and max are added to the description instead.
+func Schema(ss interface{}) ([]byte, error) { return NamingVerbatim.Schema(ss) }
+
+// Schema is like the package function Schema, but names the properties as naming names the flags.
+func (naming Naming) Schema(ss interface{}) ([]byte, error) {
+	sstype := reflect.TypeOf(ss)
+	if sstype.Kind() != reflect.Ptr || sstype.Elem().Kind() != reflect.Struct {
+		panic("sflag.Schema was not provided a pointer to a struct")
+	}
+	sstype = sstype.Elem()
+
+	var doc struct {
+		Schema               string           `json:"$schema"`
+		Description          string           `json:"description,omitempty"`
+		Type                 string           `json:"type"`
+		Properties           schemaProperties `json:"properties"`
+		Required             []string         `json:"required,omitempty"`
+		AdditionalProperties bool             `json:"additionalProperties"`
+	}
+	doc.Schema = "https://json-schema.org/draft/2020-12/schema"
+	doc.Type = "object"
+	doc.Properties.props = map[string]schemaProperty{}
+	if pp, ok := sstype.FieldByName("Usage"); ok {
+		doc.Description = (string)(pp.Tag)
+	}
+
+	for _, ff := range planFor(sstype, naming).fields {
+		if !ff.supported || ff.hidden() {
+			continue
+		}
+		typ := ff.typ
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		prop := schemaProperty{Type: schemaType(typ), Description: ff.description(), WriteOnly: ff.secret()}
+		if isList(typ) {
+			prop.Items = &schemaProperty{Type: schemaType(typ.Elem())}
+		}
+		if ff.hasDefault && (ff.typ.Kind() != reflect.Ptr || ff.ptrDefault()) && !ff.secret() {
+			prop.Default = schemaValue(field{typ: typ, def: ff.def})
+		}
+		if names := enumNames(typ); names != nil && ff.enum() == nil {
+			for _, name := range names {
+				buf, _ := json.Marshal(name)
+				if prop.Items != nil {
+					prop.Items.Enum = append(prop.Items.Enum, buf)
+				} else {
+					prop.Enum = append(prop.Enum, buf)
+				}
+			}
+		}
+		for _, choice := range ff.enum() {
+			if prop.Items != nil { // the choices apply to each element
+				prop.Items.Enum = append(prop.Items.Enum, schemaValue(field{typ: typ.Elem(), def: choice}))
+				continue
+			}
+			prop.Enum = append(prop.Enum, schemaValue(field{typ: typ, def: choice}))
+		}
+		if prop.Type == "string" { // units, e.g. max=1GiB, which minimum and maximum cannot check on strings
+			if limit, ok := ff.opts["min"]; ok {
+				prop.Description += " (at least " + limit + ")"
+			}
+			if limit, ok := ff.opts["max"]; ok {
+				prop.Description += " (at most " + limit + ")"
+			}
+		} else {
+			if limit, ok := ff.opts["min"]; ok {
+				prop.Minimum = schemaNumber(limit)
+			}
+			if limit, ok := ff.opts["max"]; ok {
+				prop.Maximum = schemaNumber(limit)
+			}
+		}
+		if _, ok := ff.opts["required"]; ok {
+			doc.Required = append(doc.Required, ff.flagname)
+		}
+		doc.Properties.names = append(doc.Properties.names, ff.flagname)
+		doc.Properties.props[ff.flagname] = prop
+	}
+	return json.MarshalIndent(&doc, "", "  ")
+}
+
+// schemaType maps the Go type of a member to a JSON Schema type.
+func schemaType(typ reflect.Type) string {
+	switch {
+	case isList(typ):
+		return "array"
+	case isText(typ) || typ == durationType:
+		return "string"
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	}
+	return "string"
+}
+
+// schemaValue returns the JSON for the value Parse would derive from ff.def.
+func schemaValue(ff field) json.RawMessage {
+	vv, _ := parseDefault(ff)
+	buf, err := json.Marshal(jsonNative(vv))
+	if err != nil { // NaN and infinities have no JSON form
+		buf, _ = json.Marshal(ff.def)
+	}
+	return buf
+}
+
+// schemaNumber returns limit as a JSON number, or nil if it is not one.
+func schemaNumber(limit string) json.RawMessage {
+	fnum, err := strconv.ParseFloat(limit, 64)
+	if err != nil {
+		return nil
+	}
+	buf, err := json.Marshal(fnum)
+	if err != nil {
+		return nil
+	}
+	return buf
+}
@@ -6,19 +6,484 @@ package sflag
 
 import (
 	"flag"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"reflect"
+	"runtime/debug"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unicode/utf8"
 )
 
-var (
-	visited map[string]bool
+// Source records where the value of a flag came from.
+type Source int
+
+const (
+	SourceUnset       Source = iota // not touched by sflag, member keeps whatever it held before Parse
+	SourceDefault                   // default value from the struct tag
+	SourceCommandLine               // set on the commandline (or the Args member)
+	SourceFile                      // read from the file named by --Name-file or an @file: value
+	SourcePrompt                    // answered when Parse asked for a missing [required] value (see Parser.Prompt)
 )
 
-func noteVisited(_flag *flag.Flag) {
-	visited[_flag.Name] = true
+func (src Source) String() string {
+	switch src {
+	case SourceDefault:
+		return "default"
+	case SourceCommandLine:
+		return "commandline"
+	case SourceFile:
+		return "file"
+	case SourcePrompt:
+		return "prompt"
+	}
+	return "unset"
+}
+
+// Parser records the outcome of parsing an options struct.  Parse and Parse2 return one.
+// To change how parsing is done, set the exported members of a Parser and call its Parse method.
+type Parser struct {
+	Strict     bool             // panic if a tagged member cannot be bound to a flag, instead of skipping it
+	Warn       func(msg string) // if set, called with each warning: a tagged member that cannot be bound (otherwise skipped silently), a deprecated flag or a failed Watch reload (otherwise printed to stderr)
+	All        bool             // list [hidden] flags in the Usage member and flag usage too, as --help-all does
+	Naming     Naming           // how flag names derive from member names; render with p.Naming.Args, Encode and Schema to match
+	IgnoreCase bool             // accept flag names in any case, with or without dashes and underscores, e.g. --some-file for --SomeFile
+	FlagSet    *flag.FlagSet    // if set, e.g. to flag.CommandLine, define the flags on it and parse with it, instead of a private FlagSet; its Usage then hides -file variants and aliases, but its PrintDefaults lists them
+	Prompt     bool             // ask for [required] values that were not given, if stdin is a terminal (or PromptIn is set)
+	PromptIn   io.Reader        // if set, read answers from it instead of stdin, e.g. in tests; [secret] answers are then not hidden
+	PromptOut  io.Writer        // if set, write prompts to it instead of stderr
+
+	// OnReload, if set, is called by Reload for each [reloadable] member it changed, with the flag name and the old and new values.
+	OnReload func(flagname string, old, new interface{})
+
+	registered []interface{}   // option structs added by Register, parsed along with the argument of Parse
+	roots      []reflect.Value // the structs of the last parse, which fields index by field.root
+	flags      *flag.FlagSet
+	visited    map[string]bool
+	sources    map[string]Source
+	fields     map[string]field // by flagname
+	order      []string         // flagnames in struct order
+	fromFile   map[string]bool  // flagnames whose value was read from a file
+	aliases    map[string]bool  // deprecated alias flagnames, left out of the flag usage
+	hidden     map[string]bool  // flagnames of [hidden] members and their -file variants, left out of the flag usage unless All
+	owners     map[string]field // the member behind each flagname sflag defined, including -file variants and aliases
+	members    map[string]bool  // flagnames of all members, which -file variants give way to
+	err        error            // deferred parse error of a [secret] member
+	argv       []string         // the arguments parsed, which Reload parses again
+	files      map[string]bool  // paths that values were read from, which Watch checks for changes
+	mu         sync.RWMutex     // held by Reload while it updates members, and by View
+	smu        sync.RWMutex     // guards sources, which Reload updates, for Source, IsSet, Dump and Args
+	reloading  bool             // parse of Reload, which parses argv and leaves the hooks to Reload
+}
+
+func (p *Parser) noteVisited(_flag *flag.Flag) {
+	p.visited[_flag.Name] = true
+	p.sources[_flag.Name] = SourceCommandLine
+	if p.fromFile[_flag.Name] {
+		p.sources[_flag.Name] = SourceFile
+	}
+}
+
+// noteField records a member that was bound to the flag vv, and registers the --Name-file variant (unless a member has that flag name) and the deprecated aliases of the flag.
+func (p *Parser) noteField(ff field, vv *value, hasDefault bool) {
+	p.fields[ff.flagname] = ff
+	p.order = append(p.order, ff.flagname)
+	p.sources[ff.flagname] = SourceUnset
+	if hasDefault {
+		p.sources[ff.flagname] = SourceDefault
+	}
+	twin := ff.flagname + "-file"
+	if p.members[twin] { // e.g. --some-file of member SomeFile with NamingKebab, which wins over the variant of Some
+		twin = ""
+	} else {
+		p.define(fileValue{vv}, twin, " <--file to read the value of --"+ff.flagname+" from", ff)
+	}
+	if ff.hidden() {
+		p.hidden[ff.flagname] = true
+		if twin != "" {
+			p.hidden[twin] = true
+		}
+	}
+
+	until, err := ff.until()
+	if err != nil {
+		panic(err)
+	}
+	for _, alias := range ff.aliases() {
+		p.aliases[alias] = true
+		p.define(aliasValue{vv, alias, until}, alias, " <--deprecated, use --"+ff.flagname, ff)
+	}
+}
+
+// define defines the flag name for the member ff, and panics naming both members if another member of the parse already defined it.
+func (p *Parser) define(value flag.Value, name, usage string, ff field) {
+	if prior, ok := p.owners[name]; ok {
+		panic(fmt.Sprintf("sflag: flag -%s of %s.%s conflicts with %s.%s", name, p.roots[ff.root].Type(), ff.name, p.roots[prior.root].Type(), prior.name))
+	}
+	p.owners[name] = ff
+	p.flags.Var(value, name, usage)
+}
+
+// warn passes msg to p.Warn, or prints it to stderr if there is no Warn.
+func (p *Parser) warn(msg string) {
+	if p.Warn != nil {
+		p.Warn(msg)
+		return
+	}
+	fmt.Fprintln(p.flags.Output(), msg)
+}
+
+// printUsage is the flag usage of Parse, which prints the flags like flag.PrintDefaults,
+// except deprecated aliases, -file variants, which it explains once, and [hidden] flags unless all is set.
+func (p *Parser) printUsage(all bool) {
+	fmt.Fprintf(p.flags.Output(), "Usage of %s:\n", p.flags.Name())
+	shown := flag.NewFlagSet(p.flags.Name(), flag.ContinueOnError)
+	shown.SetOutput(p.flags.Output())
+	twins := false
+	p.flags.VisitAll(func(_flag *flag.Flag) {
+		if p.twin(_flag.Name) {
+			twins = true
+		} else if !p.aliases[_flag.Name] && (all || !p.hidden[_flag.Name]) { // not p.listed, as --help-all overrides p.All
+			shown.Var(_flag.Value, _flag.Name, _flag.Usage)
+			shown.Lookup(_flag.Name).DefValue = _flag.DefValue
+		}
+	})
+	shown.PrintDefaults()
+	if twins {
+		fmt.Fprintln(p.flags.Output(), "  Flags -X also take -X-file=path, or the value @file:path, to read the value from a file (@@file: escapes a value starting with @file:)")
+	}
+}
+
+// twin reports whether flagname is the --Name-file variant of a member flag.
+func (p *Parser) twin(flagname string) bool {
+	ff, ok := p.owners[flagname]
+	return ok && flagname == ff.flagname+"-file"
+}
+
+// value is the flag.Value of a member.  It reads @file: values and keeps [secret] values out of error messages.
+// Nil pointers (to the member, or to the nested struct holding it) are allocated when the flag is set,
+// and slices are replaced by the first occurrence of the flag and appended to by repeated ones.
+type value struct {
+	p   *Parser
+	ff  field
+	set bool // flag occurred on the commandline before
+}
+
+func (vv *value) Set(s string) error {
+	if path := strings.TrimPrefix(s, "@file:"); path != s {
+		content, err := readValueFile(path)
+		if err != nil {
+			return err
+		}
+		s = content
+		vv.p.fromFile[vv.ff.flagname] = true
+		vv.p.files[path] = true
+	} else if escapedFile(s) {
+		s = s[1:] // @@file:x is the value @file:x
+	}
+	err := vv.assign(s)
+	if err != nil && vv.ff.secret() { // the flag package would quote the offending value in its panic
+		if vv.p.err == nil {
+			vv.p.err = fmt.Errorf("invalid value %s for flag -%s: %v", redacted, vv.ff.flagname, err)
+		}
+		return nil
+	}
+	return err
+}
+
+func (vv *value) assign(s string) error {
+	typ := vv.ff.typ
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if vv.ff.counter() && (s == "true" || s == "false") { // bare -v, which the flag package passes as for bool flags
+		s = vv.count(s == "true")
+	}
+	parsed := reflect.New(typ).Elem()
+	if err := setValue(parsed, s); err != nil {
+		return err
+	}
+	target := vv.p.target(vv.ff, true)
+	if target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(typ))
+		}
+		target = target.Elem()
+	}
+	if vv.set && isList(typ) {
+		parsed = reflect.AppendSlice(target, parsed)
+	}
+	target.Set(parsed)
+	vv.set = true
+	return nil
+}
+
+func (vv *value) String() string {
+	if vv == nil || vv.p == nil { // zero value, as used by flag.isZeroValue, also inside a zero aliasValue
+		return ""
+	}
+	if vv.ff.secret() {
+		return redacted
+	}
+	if target := vv.p.target(vv.ff, false); target.IsValid() {
+		return formatValue(target)
+	}
+	return ""
+}
+
+// count returns the value of a [count] member after one more bare occurrence of its flag, or "0" when reset by --Foo=false.
+func (vv *value) count(more bool) string {
+	if !more {
+		return "0"
+	}
+	current := vv.p.target(vv.ff, false)
+	if current.IsValid() && current.Kind() == reflect.Ptr {
+		current = current.Elem() // invalid if nil
+	}
+	if !current.IsValid() {
+		return "1"
+	}
+	switch current.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(current.Uint()+1, 10)
+	}
+	return strconv.FormatInt(current.Int()+1, 10)
+}
+
+func (vv *value) IsBoolFlag() bool {
+	if vv.ff.counter() {
+		return true
+	}
+	typ := vv.ff.typ
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Bool && !isText(typ)
+}
+
+// target returns the member bound to ff, walking through nested structs.  A nil pointer to a nested struct yields the
+// invalid Value, unless alloc is set, in which case the struct is allocated and the defaults of its members applied.
+func (p *Parser) target(ff field, alloc bool) reflect.Value {
+	vv := p.roots[ff.root]
+	for depth, ii := range ff.index {
+		if vv.Kind() == reflect.Ptr {
+			if vv.IsNil() {
+				if !alloc {
+					return reflect.Value{}
+				}
+				vv.Set(reflect.New(vv.Type().Elem()))
+				p.applyDefaults(ff.root, ff.index[:depth])
+			}
+			vv = vv.Elem()
+		}
+		vv = vv.Field(ii)
+	}
+	return vv
+}
+
+// applyDefaults sets the members below the nested struct at prefix in p.roots[root] to their tag defaults, once that struct is allocated.
+func (p *Parser) applyDefaults(root int, prefix []int) {
+	for _, flagname := range p.order {
+		ff := p.fields[flagname]
+		if ff.root != root || len(ff.index) <= len(prefix) || !reflect.DeepEqual(ff.index[:len(prefix)], prefix) {
+			continue
+		}
+		if vv := lookup(p.roots[root], ff.index); vv.IsValid() && ff.setDefault(vv) {
+			p.sources[flagname] = SourceDefault
+		}
+	}
+}
+
+// setDefault assigns the tag default to the member vv, and reports whether it did.
+// Pointer members get the default only if tagged [default], and only while nil.
+func (ff field) setDefault(vv reflect.Value) bool {
+	switch {
+	case !ff.hasDefault:
+		return false
+	case vv.Kind() != reflect.Ptr:
+		vv.Set(ff.defaultValue())
+	case !ff.ptrDefault() || !vv.IsNil():
+		return false
+	default:
+		vv.Set(reflect.New(ff.defValue.Type()))
+		vv.Elem().Set(ff.defaultValue())
+	}
+	return true
+}
+
+// defaultValue returns a copy of ff.defValue, so that slice members do not share the array of the cached plan.
+func (ff field) defaultValue() reflect.Value {
+	if ff.defValue.Kind() != reflect.Slice || ff.defValue.IsNil() {
+		return ff.defValue
+	}
+	vv := reflect.MakeSlice(ff.defValue.Type(), ff.defValue.Len(), ff.defValue.Len())
+	reflect.Copy(vv, ff.defValue)
+	return vv
+}
+
+// helpAllValue is the flag.Value of --help-all, which prints the flag usage including [hidden] flags, like -help does without them.
+type helpAllValue struct{ p *Parser }
+
+func (hv helpAllValue) Set(string) error {
+	hv.p.printUsage(true)
+	if hv.p.flags.ErrorHandling() == flag.ExitOnError {
+		os.Exit(0)
+	}
+	panic(flag.ErrHelp) // as the flag package does for -help, with PanicOnError
+}
+
+func (hv helpAllValue) String() string   { return "" }
+func (hv helpAllValue) IsBoolFlag() bool { return true }
+
+// versionValue is the flag.Value of --version, which prints the program name and version to stdout and exits.
+type versionValue struct {
+	p       *Parser
+	version string
+}
+
+func (vv versionValue) Set(string) error {
+	fmt.Println(filepath.Base(vv.p.flags.Name()), vv.version)
+	if vv.p.FlagSet == nil || vv.p.flags.ErrorHandling() == flag.ExitOnError {
+		os.Exit(0)
+	}
+	panic(flag.ErrHelp) // as for -help, so that callers with their own FlagSet need not exit
+}
+
+func (vv versionValue) String() string   { return "" }
+func (vv versionValue) IsBoolFlag() bool { return true }
+
+// buildVersion describes the running binary from its build info: the module version, and the VCS revision and
+// whether the tree had local changes, if the go command recorded them.
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(unknown)"
+	}
+	version := info.Main.Version // (devel) if built inside the module
+	if version == "" {
+		version = "(devel)"
+	}
+	revision, modified := "", false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	switch {
+	case revision != "" && modified:
+		version += " (" + revision + ", dirty)"
+	case revision != "":
+		version += " (" + revision + ")"
+	}
+	return version
+}
+
+// aliasValue is the flag.Value of a deprecated alias of --Name.  It warns, or fails once the until date has come, and then sets --Name.
+type aliasValue struct {
+	*value
+	alias string
+	until time.Time // zero if the alias does not expire
+}
+
+func (av aliasValue) Set(s string) error {
+	if !av.until.IsZero() && !time.Now().Before(av.until) {
+		if av.p.err == nil { // reported after parsing, like [secret] errors, so that the value is not shown
+			av.p.err = fmt.Errorf("flag -%s was removed on %s, use -%s", av.alias, av.until.Format(dateLayout), av.ff.flagname)
+		}
+		return nil
+	}
+	msg := "sflag: flag -" + av.alias + " is deprecated, use -" + av.ff.flagname
+	if !av.until.IsZero() {
+		msg += " (-" + av.alias + " stops working on " + av.until.Format(dateLayout) + ")"
+	}
+	av.p.warn(msg)
+	return av.p.flags.Set(av.ff.flagname, s) // marks --Name itself as visited
+}
+
+// fileValue is the flag.Value of --Name-file, which sets --Name from the contents of the named file.
+type fileValue struct{ vv *value }
+
+func (fv fileValue) Set(path string) error {
+	content, err := readValueFile(path)
+	if err != nil {
+		return err
+	}
+	fv.vv.p.fromFile[fv.vv.ff.flagname] = true
+	fv.vv.p.files[path] = true
+	return fv.vv.p.flags.Set(fv.vv.ff.flagname, escapeFileValue(content)) // marks --Name itself as visited
+}
+
+func (fv fileValue) String() string { return "" }
+
+// escapedFile reports whether s is an @file: value with one or more @ prepended, which Set strips one @ from.
+func escapedFile(s string) bool {
+	return strings.HasPrefix(s, "@@") && strings.HasPrefix(strings.TrimLeft(s, "@"), "file:")
+}
+
+// escapeFileValue returns the flag value that sets a member to s, which is s unless it would be read as @file:path.
+func escapeFileValue(s string) string {
+	if escapedFile(s) || strings.HasPrefix(s, "@file:") {
+		return "@" + s
+	}
+	return s
+}
+
+// readValueFile returns the contents of path with the trailing newline trimmed.
+func readValueFile(path string) (string, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(buf), "\r\n"), nil
+}
+
+// Source reports where the value of a flag came from.  name may be either the flag name or the member name.
+func (p *Parser) Source(name string) Source {
+	p.smu.RLock()
+	defer p.smu.RUnlock()
+	if src, ok := p.sources[name]; ok {
+		return src
+	}
+	for flagname, ff := range p.fields {
+		if ff.name == name {
+			return p.sources[flagname]
+		}
+	}
+	return SourceUnset
+}
+
+// IsSet reports whether a flag was given, on the commandline, from a file or at a prompt, rather than left at its default.
+// name may be either the flag name or the member name.
+func (p *Parser) IsSet(name string) bool {
+	src := p.Source(name)
+	return src == SourceCommandLine || src == SourceFile || src == SourcePrompt
+}
+
+// Dump writes one line per flag to w, listing name, current value and where that value came from.  See View about Reload.
+func (p *Parser) Dump(w io.Writer) {
+	p.smu.RLock()
+	defer p.smu.RUnlock()
+	for _, flagname := range p.order {
+		vv := p.target(p.fields[flagname], false)
+		value := "<nil>"
+		switch {
+		case p.fields[flagname].secret():
+			value = redacted
+		case vv.IsValid() && (vv.Kind() != reflect.Ptr || !vv.IsNil()):
+			value = formatValue(vv)
+		}
+		fmt.Fprintf(w, "--%s=%s\t# %s\n", flagname, value, p.sources[flagname])
+	}
 }
 
 // Parse iterates through the members of the struct.  Notes:
@@ -27,174 +492,490 @@ func noteVisited(_flag *flag.Flag) {
 //     Normally, the rightmost pipe char in the tag is used to delineate between Description (on left) and Default value (on right).
 //     (You can override delineator to the first char of the tag (after eliminating leading whitespace) if such char is not alphabetic).
 //     Fields with no tag or whitespace-only tags are ignored.
+//     Tagged fields that cannot be bound (unexported, or of unsupported type) are skipped, unless Parser.Strict is set.
+//     Supported member types are string, bool, all int, uint and float kinds, time.Duration, encoding.TextUnmarshaler implementations, slices of those, and pointers to any of these.
+//     Network members (net.IP, net.IPNet, netip.Addr, netip.AddrPort, netip.Prefix, url.URL) are checked as they are parsed, e.g. "listen on | 127.0.0.1:8080".
+//     Members of named types whose values are declared with RegisterEnum take the value names, ignoring case.
+//     Members of type ByteSize, Quantity and Percent take human units, e.g. "cache size | 512KiB", --Items=10k or --Fill=75%.
+//     Slice flags take comma-separated values, e.g. --Peers=a,b, and repeating the flag appends.  Quote an element to keep commas and spaces, e.g. --Peers='"a, b",c'.
+//     Tagged struct members (and pointers to structs) contribute their own tagged members as flags --Parent.Child.
 //     Non-nil pointer fields are ignored.
-//     Nil pointer fields will be left nil if that flag is not set on commandline (and the tag is not parsed for a default value).
+//     Nil pointer fields will be left nil if that flag is not set on commandline, unless tagged [default], e.g. "retry count [default] | 3".
+//     A nil pointer to a nested struct is allocated (with its defaults) when one of its flags is set.
+//     A trailing [opt,key=value] group in the description sets options, e.g. "DB password [secret] | " masks the value in Usage, dumps and parse errors.
+//     Options [required], [min=N], [max=N] and [enum=a/b/c] are checked after parsing, Parse panics if they are violated.
+//     With Parser.Prompt, missing [required] values are asked for on a terminal instead, hiding [secret] answers, or failing if they would show.
+//     Deprecated flag names still parse when listed as [alias=Old1/Old2], with a warning (see Parser.Warn), or an error from the date given as [until=YYYY-MM-DD].
+//     Members tagged [hidden] are bound as usual, but left out of the Usage member and -help, unless Parser.All is set; --help-all, defined if there are any, lists them too.
+//     Integer members tagged [count] count the occurrences of their flag, e.g. -v -v -v sets 3, while --v=5 sets 5 directly.
+//     Parser.Reload parses the same arguments again, re-reading value files, and updates the members tagged [reloadable], e.g. on SIGHUP (see Parser.Watch).
+//     A [hook=Method] option calls Method() error of the struct holding the member once Parse has set the member, from the commandline, a file or the default.
+//     Options structs implementing Validator are validated after the tag options are checked and before the hooks run, and those implementing AfterParser are called last.
+//     Every flag --Foo also accepts --Foo-file=path (unless a member has that flag name), or a value of the form @file:path, to read the value from a file (trailing newline trimmed).
+//     A value starting with @file: is given with one more @, e.g. --Foo=@@file:x sets @file:x.  The flag usage explains the -file variants once instead of listing them.
 //     Flags starting with lowercase letter require that the coresponding member ends in single underscore.
+//     Parser.Naming derives flag names in other styles, e.g. --some-file for member SomeFile, and a [name=x] option sets the flag name of a member.
 //     Provide string member Usage initialized to brief program description.  Parse will append member descriptions to that string.
 //     Provide []string member Args if you want to want to retrieve unconsumed flags.
 //     Initialize []string member Args to the string array you want to parse instead of os.Args[1:].
-func Parse(ss interface{}) { parseInternal(ss, true) }
+//     Provide untagged string member Version to get a --version flag, which prints it and exits.  Parse sets it from the build info
+//     (module version, VCS revision and dirty state) unless it holds a version already, e.g. one given by -ldflags=-X.
+//     Provide map[string]bool member Set to learn which flags were given (on the commandline or from a file), keyed by flag name.
+//     Structs added with Register are parsed in the same pass: a flag name defined by two of them panics, each Usage member lists the flags of all,
+//     the first non-empty Args member overrides os.Args[1:], every Args member gets the unconsumed flags, and each Set member its own flags.
+//     Set Parser.FlagSet to flag.CommandLine (or another FlagSet) to parse flags defined by libraries too; they are listed in Usage.
+//     The returned Parser reports where each value came from (see Parser.Source and Parser.Dump).
+func Parse(ss interface{}) *Parser {
+	p := &Parser{}
+	p.parseInternal(ss, true)
+	return p
+}
+
+// Parse is the package-level Parse, using the settings in p.  ss may be nil if the structs to parse were added with Register.
+func (p *Parser) Parse(ss interface{}) { p.parseInternal(ss, true) }
+
+// Register adds the option structs pointed to by ss to those that Parse of p parses along with its argument, so that
+// packages can each own an options struct.  All are parsed in one pass over the commandline.  It returns p.
+func (p *Parser) Register(ss ...interface{}) *Parser {
+	p.registered = append(p.registered, ss...)
+	return p
+}
+
+// Register returns a Parser that parses the option structs pointed to by ss together, e.g. sflag.Register(&dbOpts, &httpOpts).Parse(&opt).
+func Register(ss ...interface{}) *Parser { return (&Parser{}).Register(ss...) }
 
 // Parse2 is identical to Parse, except panics if there is both (1) a boolean flag and (2) a standalone true/false argument.
 // It reminds you to use "--Foo=true" syntax (instead of "--Foo true" which would terminate the stdlib's flag processing for bool flag Foo, which is considered set by its presence alone).
 // The downside of using this func is that unrelated presence of true/false results in progam panic.
-func Parse2(ss interface{}) { parseInternal(ss, false) }
+func Parse2(ss interface{}) *Parser {
+	p := &Parser{}
+	p.parseInternal(ss, false)
+	return p
+}
 
-func parseInternal(ss interface{}, _permitStandaloneBool bool) {
-	visited = make(map[string]bool)
-	pointers := map[string]interface{}{}
-	if reflect.TypeOf(ss).Kind() != reflect.Ptr {
-		panic("sflag.Parse was not provided a pointer arg")
+// field is a struct member that sflag turns into a flag, with its tag split into description and default value.
+type field struct {
+	index      []int  // path from the options struct through nested structs
+	root       int    // which of the parsed structs holds the member, set by Parse (see Parser.Register)
+	name       string // member name, dotted for members of nested structs
+	flagname   string
+	typ        reflect.Type
+	desc       string            // left of the delineator
+	def        string            // right of the delineator, or the whole tag if there is none
+	hasDefault bool              // tag contained the delineator
+	opts       map[string]string // options from a trailing [opt,key=value] group in the description
+	supported  bool              // Parse knows how to bind the member
+	skip       string            // why Parse cannot bind the member, if it cannot
+	defValue   reflect.Value     // def parsed for the type, if hasDefault
+	usage      string            // usage text registered with the flag package
+}
+
+// plan is the analysed layout of an options struct type, compiled once per type (see planFor).
+type plan struct {
+	naming      Naming
+	fields      []field // members considered for flags, in struct order
+	moreusage   string  // lines Parse appends to the Usage member
+	hiddenusage string  // lines of [hidden] members, appended only if Parser.All is set
+	hasBoolArg  bool
+}
+
+// planKey identifies a plan in the cache.
+type planKey struct {
+	sstype reflect.Type
+	naming Naming
+}
+
+var plans sync.Map // planKey -> *plan
+
+// planFor returns the cached plan for sstype and naming, compiling it on first use.
+func planFor(sstype reflect.Type, naming Naming) *plan {
+	key := planKey{sstype, naming}
+	if pl, ok := plans.Load(key); ok {
+		return pl.(*plan)
 	}
-	sstype := reflect.TypeOf(ss).Elem()
-	ssvalue := reflect.ValueOf(ss).Elem()
+	pl, _ := plans.LoadOrStore(key, compilePlan(sstype, naming))
+	return pl.(*plan)
+}
 
-	if sstype.Kind() != reflect.Struct {
-		panic("sflag.Parse was not provided a pointer to a struct")
+// description returns the descriptive part of the tag, which is all of it when there is no delineator.
+func (ff field) description() string {
+	if ff.hasDefault {
+		return ff.desc
 	}
+	return ff.def
+}
 
-	var argsiface interface{}
-	args := make([]string, len(os.Args)-1)
-	copy(args, os.Args[1:])
+func (ff field) secret() bool {
+	_, ok := ff.opts["secret"]
+	return ok
+}
 
-	progname := os.Args[0]
-	if pp, ok := sstype.FieldByName("Args"); ok {
-		if pp.Type.String() == "[]string" { // caller wanted to override os.Args and/or retrieve unconsumed flags
-			vv := ssvalue.FieldByName("Args")
-			if len(*vv.Addr().Interface().(*[]string)) == 0 {
-			} else { // caller wanted to override os.Args
-				args = make([]string, len(*vv.Addr().Interface().(*[]string)))
-				copy(args, *vv.Addr().Interface().(*[]string))
-			}
-			argsiface = vv.Addr().Interface()
+func (ff field) ptrDefault() bool {
+	_, ok := ff.opts["default"]
+	return ok
+}
+
+// aliases returns the deprecated flag names from the alias option, or nil if there is none.
+func (ff field) aliases() []string {
+	var list []string
+	for _, alias := range strings.Split(ff.opts["alias"], "/") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			list = append(list, alias)
 		}
 	}
+	return list
+}
 
-	moreusage := ""
-	hasBoolArg := false
-	flags := *flag.NewFlagSet(progname, flag.PanicOnError)
+// dateLayout is the layout of the until option.
+const dateLayout = "2006-01-02"
+
+// until returns the date from the until option, from which on the aliases are rejected, or the zero Time if there is none.
+func (ff field) until() (time.Time, error) {
+	date, ok := ff.opts["until"]
+	if !ok {
+		return time.Time{}, nil
+	}
+	day, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("sflag: bad until=%s option on member %s, want YYYY-MM-DD", date, ff.name)
+	}
+	return day, nil
+}
+
+func (ff field) reloadable() bool {
+	_, ok := ff.opts["reloadable"]
+	return ok
+}
+
+func (ff field) hidden() bool {
+	_, ok := ff.opts["hidden"]
+	return ok
+}
 
+func (ff field) counter() bool {
+	_, ok := ff.opts["count"]
+	return ok
+}
+
+// tagOptions lists the options understood in a trailing [opt,key=value] group of the description.
+var tagOptions = map[string]bool{
+	"secret":     true, // mask value in Usage, dumps and parse errors
+	"default":    true, // apply the default to a pointer member, which is otherwise left nil
+	"required":   true, // flag must be given on the commandline (or from a file)
+	"min":        true, // lowest accepted value of a numeric member
+	"max":        true, // highest accepted value of a numeric member
+	"enum":       true, // accepted values, separated by slashes, e.g. enum=fast/slow
+	"count":      true, // integer member counting the occurrences of its flag, e.g. -v -v -v
+	"alias":      true, // deprecated former flag names, separated by slashes, e.g. alias=OutData
+	"until":      true, // date from which the aliases are rejected instead of warned about, e.g. until=2027-01-01
+	"hidden":     true, // bound as usual, but left out of Usage unless Parser.All is set or --help-all given
+	"reloadable": true, // updated by Parser.Reload
+	"hook":       true, // method of the struct holding the member, called once Parse set the member, e.g. hook=OpenLog
+	"name":       true, // flag name to use instead of the one derived from the member name, e.g. name=in
+}
+
+// splitOptions removes a trailing [opt,key=value] group from the description text.
+// Text whose trailing group contains anything but known options is left alone.
+func splitOptions(text string) (string, map[string]string) {
+	if !strings.HasSuffix(text, "]") {
+		return text, nil
+	}
+	open := strings.LastIndex(text, "[")
+	if open < 0 {
+		return text, nil
+	}
+	opts := map[string]string{}
+	for _, opt := range strings.Split(text[open+1:len(text)-1], ",") {
+		key, value := strings.TrimSpace(opt), ""
+		if nn := strings.Index(key, "="); nn >= 0 {
+			key, value = strings.TrimSpace(key[:nn]), strings.TrimSpace(key[nn+1:])
+		}
+		if !tagOptions[key] {
+			return text, nil
+		}
+		opts[key] = value
+	}
+	return strings.TrimSpace(text[:open]), opts
+}
+
+// Tag is the parsed form of a struct tag, for tools such as cmd/sflaggen that work from source rather than reflection.
+type Tag struct {
+	Flag        string            // flag name derived from the member name as NamingVerbatim does, or given by the name option
+	Description string            // left of the delineator
+	Default     string            // right of the delineator, or the whole tag if there is none
+	HasDefault  bool              // tag contained the delineator
+	Options     map[string]string // options from a trailing [opt,key=value] group in the description
+}
+
+// ParseTag splits the tag of the member called name the way Parse does.  It returns false for tags Parse ignores.
+func ParseTag(name, tag string) (Tag, bool) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return Tag{}, false
+	}
+
+	_, nn := utf8.DecodeRuneInString(tag)
+	splitChar := tag[0:nn]
+	if strings.Contains("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ", splitChar) {
+		splitChar = "|"
+	} else {
+		tag = tag[len(splitChar):]
+	}
+
+	tt := Tag{Flag: NamingVerbatim.flagName(name)}
+	lastSplit := strings.LastIndex(tag, splitChar)
+	switch lastSplit > -1 {
+	case false:
+		tt.Default = strings.TrimSpace(tag)
+	case true:
+		tt.Description, tt.Default = strings.TrimSpace(tag[:lastSplit]), strings.TrimSpace(tag[(lastSplit+1):])
+		tt.HasDefault = true
+	}
+	switch tt.HasDefault {
+	case false:
+		tt.Default, tt.Options = splitOptions(tt.Default)
+	case true:
+		tt.Description, tt.Options = splitOptions(tt.Description)
+	}
+	if flagname := tt.Options["name"]; flagname != "" {
+		tt.Flag = flagname
+	}
+	return tt, true
+}
+
+// compilePlan splits the tags of the members of sstype and works out how Parse binds each of them.
+func compilePlan(sstype reflect.Type, naming Naming) *plan {
+	pl := &plan{naming: naming}
+	pl.compile(sstype, nil, "", "", map[reflect.Type]bool{sstype: true})
+	return pl
+}
+
+// compile adds the members of the struct type sstype, found at index below the options struct, to pl.
+// Members of nested structs get the names and flag names of their parents as prefix.
+func (pl *plan) compile(sstype reflect.Type, index []int, prefix, flagprefix string, outer map[reflect.Type]bool) {
 	for ii := 0; ii < sstype.NumField(); ii++ {
 		pp := sstype.Field(ii)
-		vv := ssvalue.Field(ii)
 		switch {
 		case pp.Anonymous:
 			continue // Skip embedded fields
-		case pp.Name == "Usage":
+		case index == nil && pp.Name == "Usage":
 			continue // Not a flag
-		case pp.Type.String() == "[]string":
-			continue // Already handled Args, and not interested in other such members
-		case (pp.Type.Kind() == reflect.Ptr) && (vv.Elem().Kind() != reflect.Invalid):
-			continue // Ignore non-nil pointer members
+		case index == nil && pp.Name == "Args" && pp.Type.String() == "[]string":
+			continue // Already handled Args
+		case index == nil && pp.Name == "Set" && pp.Type.String() == "map[string]bool":
+			continue // Filled after parsing
 		}
 
-		tag := strings.TrimSpace((string)(pp.Tag))
-		if tag == "" {
+		tag, ok := ParseTag(pp.Name, (string)(pp.Tag))
+		if !ok {
 			continue
 		}
+		if _, ok := tag.Options["name"]; !ok {
+			tag.Flag = pl.naming.flagName(pp.Name)
+		}
+		ff := field{index: append(index[:len(index):len(index)], ii), name: prefix + pp.Name, flagname: flagprefix + tag.Flag, typ: pp.Type,
+			desc: tag.Description, def: tag.Default, hasDefault: tag.HasDefault, opts: tag.Options}
 
-		flagname := pp.Name
-		if nn := len(pp.Name) - 1; flagname[nn] == '_' { // User wants to look for --f* instead of --F*
-			flagname = strings.ToLower(pp.Name[:1]) + pp.Name[1:nn]
-		}
-
-		_, nn := utf8.DecodeRuneInString(tag)
-		splitChar := tag[0:nn]
-		if strings.Contains("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ", splitChar) {
-			splitChar = "|"
-		} else {
-			tag = tag[len(splitChar):]
-		}
-
-		lastSplit := strings.LastIndex(tag, splitChar)
-		part0, part1 := "", ""
-		switch lastSplit > -1 {
-		case false:
-			part1 = strings.TrimSpace(tag)
-		case true:
-			part0, part1 = strings.TrimSpace(tag[:lastSplit]), strings.TrimSpace(tag[(lastSplit+1):])
-		}
-
-		if pp.Type.Kind() == reflect.Ptr {
-			switch pp.Type.String() {
-			case "*string":
-				tempstr := ""
-				pointers[flagname] = &tempstr
-				flags.StringVar(&tempstr, flagname, tempstr, "")
-			case "*int":
-				tempint := 0
-				pointers[flagname] = &tempint
-				flags.IntVar(&tempint, flagname, tempint, "")
-			case "*bool":
-				tempbool := false
-				pointers[flagname] = &tempbool
-				flags.BoolVar(&tempbool, flagname, tempbool, "")
-			case "*int64":
-				tempint64 := int64(0)
-				pointers[flagname] = &tempint64
-				flags.Int64Var(&tempint64, flagname, tempint64, "")
-			case "*float64":
-				tempfloat64 := 0.0
-				pointers[flagname] = &tempfloat64
-				flags.Float64Var(&tempfloat64, flagname, tempfloat64, "")
-			default:
-				continue
+		elem := ff.typ
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		switch {
+		case pp.PkgPath != "":
+			ff.skip = "unexported member, name it " + strings.ToUpper(pp.Name[:1]) + pp.Name[1:] + "_ to get flag --" + pp.Name
+		case nestedKind(ff.typ) && outer[elem]:
+			ff.skip = "recursive type " + pp.Type.String()
+		case nestedKind(ff.typ):
+			outer[elem] = true
+			pl.compile(elem, ff.index, ff.name+".", ff.flagname+".", outer)
+			delete(outer, elem)
+			continue
+		case !supportedKind(ff.typ):
+			ff.skip = "unsupported type " + pp.Type.String()
+		case ff.counter() && !isInteger(elem):
+			ff.skip = "[count] needs an integer member, not " + pp.Type.String()
+		}
+		ff.supported = ff.skip == ""
+		if ff.supported {
+			isPtr := ff.typ.Kind() == reflect.Ptr
+			desc := ff.desc
+			if names := enumNames(elem); names != nil {
+				desc += " (" + strings.Join(names, "|") + ")"
+			}
+			if !isPtr || ff.ptrDefault() {
+				ff.usage = " <--default, " + ff.typ.String() + " # " + desc
+				if (!isPtr && elem.Kind() == reflect.Bool && !isText(elem)) || ff.counter() {
+					pl.hasBoolArg = true
+				}
+			}
+			if ff.hasDefault {
+				ff.defValue, _ = parseDefault(field{typ: elem, def: ff.def})
+			}
+			if ff.hasDefault && (!isPtr || ff.ptrDefault()) {
+				shown := ff.def
+				if ff.secret() {
+					shown = redacted
+				}
+				line := "\n\t--" + ff.flagname + ": " + shown + " <-- Default, " + ff.typ.String() + " # " + desc
+				if ff.hidden() {
+					pl.hiddenusage += line
+				} else {
+					pl.moreusage += line
+				}
 			}
 		}
+		pl.fields = append(pl.fields, ff)
+	}
+}
 
-		if lastSplit < 0 {
-			switch pp.Type.Kind() {
-			case reflect.String:
-				flags.StringVar(vv.Addr().Interface().(*string), flagname, vv.String(), " <--default, string # "+part0)
-			case reflect.Int:
-				flags.IntVar(vv.Addr().Interface().(*int), flagname, int(vv.Int()), " <--default, int # "+part0)
-			case reflect.Bool:
-				flags.BoolVar(vv.Addr().Interface().(*bool), flagname, bool(vv.Bool()), " <--default, bool # "+part0)
-				hasBoolArg = true
-			case reflect.Int64:
-				flags.Int64Var(vv.Addr().Interface().(*int64), flagname, vv.Int(), " <--default, int64 # "+part0)
-			case reflect.Float64:
-				flags.Float64Var(vv.Addr().Interface().(*float64), flagname, vv.Float(), " <--default, float64 # "+part0)
-			default:
-				continue
+// parseDefault returns the value Parse assigns to a member from the default in its tag, and whether the default parsed.
+func parseDefault(ff field) (reflect.Value, bool) {
+	vv := reflect.New(ff.typ).Elem()
+	if err := setTagValue(vv, ff.def); err != nil {
+		return reflect.New(ff.typ).Elem(), false
+	}
+	return vv, true
+}
+
+func (p *Parser) parseInternal(ss interface{}, _permitStandaloneBool bool) {
+	p.visited = make(map[string]bool)
+	p.sources = make(map[string]Source)
+	p.fields = make(map[string]field)
+	p.order = nil
+	p.fromFile = make(map[string]bool)
+	p.aliases = make(map[string]bool)
+	p.hidden = make(map[string]bool)
+	p.owners = make(map[string]field)
+	p.files = make(map[string]bool)
+	p.err = nil
+	all := p.registered
+	if ss != nil {
+		all = append([]interface{}{ss}, all...)
+	}
+	if len(all) == 0 {
+		panic("sflag.Parse was not provided a pointer arg")
+	}
+	p.roots = nil
+	for _, ss := range all {
+		if reflect.TypeOf(ss) == nil || reflect.TypeOf(ss).Kind() != reflect.Ptr {
+			panic("sflag.Parse was not provided a pointer arg")
+		}
+		if reflect.TypeOf(ss).Elem().Kind() != reflect.Struct {
+			panic("sflag.Parse was not provided a pointer to a struct")
+		}
+		p.roots = append(p.roots, reflect.ValueOf(ss).Elem())
+	}
+
+	var argsifaces []*[]string // of all structs, which all get the unconsumed flags
+	overridden := false        // by the first struct with a non-empty Args
+	args := make([]string, len(os.Args)-1)
+	copy(args, os.Args[1:])
+
+	progname := os.Args[0]
+	for _, ssvalue := range p.roots {
+		if pp, ok := ssvalue.Type().FieldByName("Args"); ok {
+			if pp.Type.String() == "[]string" { // caller wanted to override os.Args and/or retrieve unconsumed flags
+				argsiface := ssvalue.FieldByName("Args").Addr().Interface().(*[]string)
+				if len(*argsiface) == 0 || overridden {
+				} else { // caller wanted to override os.Args
+					args = make([]string, len(*argsiface))
+					copy(args, *argsiface)
+					overridden = true
+				}
+				argsifaces = append(argsifaces, argsiface)
 			}
 		}
+	}
+
+	if p.reloading {
+		args = append([]string{}, p.argv...)
+	}
+
+	var plans []*plan
+	moreusage := ""
+	hasBoolArg := false
+	for _, ssvalue := range p.roots {
+		pl := planFor(ssvalue.Type(), p.Naming)
+		plans = append(plans, pl)
+		moreusage += pl.moreusage
+		if p.All {
+			moreusage += pl.hiddenusage
+		}
+		hasBoolArg = hasBoolArg || pl.hasBoolArg
+	}
+	flags := p.FlagSet
+	if flags == nil {
+		flags = flag.NewFlagSet(progname, flag.PanicOnError)
+	}
+	flags.VisitAll(func(_flag *flag.Flag) { // defined by others, e.g. libraries on flag.CommandLine
+		moreusage += "\n\t--" + _flag.Name + ": " + _flag.DefValue + " <-- Default # " + _flag.Usage
+	})
+	p.flags = flags
+	flags.Usage = func() { p.printUsage(p.All) }
+
+	p.members = make(map[string]bool)
+	hasHidden := false
+	for _, pl := range plans {
+		for _, ff := range pl.fields {
+			p.members[ff.flagname] = ff.supported
+			hasHidden = hasHidden || (ff.supported && ff.hidden())
+		}
+	}
+	if hasHidden && flags.Lookup("help-all") == nil {
+		flags.Var(helpAllValue{p}, "help-all", " <--show all flags, including hidden ones")
+	}
 
-		if lastSplit >= 0 {
-			switch pp.Type.Kind() {
-			case reflect.String:
-				vv.SetString(part1)
-				flags.StringVar(vv.Addr().Interface().(*string), flagname, part1, " <--default, string # "+part0)
-			case reflect.Int:
-				inum, _ := strconv.ParseInt(part1, 10, 64)
-				vv.SetInt(inum)
-				flags.IntVar(vv.Addr().Interface().(*int), flagname, int(inum), " <--default, int # "+part0)
-			case reflect.Bool:
-				bnum, _ := strconv.ParseBool(part1)
-				vv.SetBool(bnum)
-				flags.BoolVar(vv.Addr().Interface().(*bool), flagname, bool(bnum), " <--default, bool # "+part0)
-				hasBoolArg = true
-			case reflect.Int64:
-				jnum, _ := strconv.ParseInt(part1, 10, 64)
-				vv.SetInt(jnum)
-				flags.Int64Var(vv.Addr().Interface().(*int64), flagname, jnum, " <--default, int64 # "+part0)
-			case reflect.Float64:
-				fnum, _ := strconv.ParseFloat(part1, 64)
-				vv.SetFloat(fnum)
-				flags.Float64Var(vv.Addr().Interface().(*float64), flagname, fnum, " <--default, float64 # "+part0)
-			default:
+	var unbound []string
+	for root, pl := range plans {
+		for _, ff := range pl.fields {
+			if !ff.supported {
+				msg := "sflag cannot bind member " + ff.name + ": " + ff.skip
+				if p.Warn != nil {
+					p.Warn(msg)
+				}
+				unbound = append(unbound, msg)
 				continue
 			}
+			ff.root = root
+			vv := lookup(p.roots[root], ff.index) // invalid below a nil nested struct, whose defaults wait until it is allocated
+			if vv.IsValid() && vv.Kind() == reflect.Ptr && !vv.IsNil() {
+				continue // Ignore non-nil pointer members
+			}
+			hasDefault := vv.IsValid() && ff.setDefault(vv)
+			_value := &value{p: p, ff: ff}
+			p.define(_value, ff.flagname, ff.usage, ff)
+			p.noteField(ff, _value, hasDefault)
 		}
+	}
 
-		if lastSplit >= 0 {
-			moreusage += "\n\t--" + flagname + ": " + part1 + " <-- Default, " + pp.Type.String() + " # " + part0
+	if p.Strict && len(unbound) > 0 {
+		panic(strings.Join(unbound, "\n"))
+	}
+
+	var versions []reflect.Value
+	for _, ssvalue := range p.roots {
+		if pp, ok := ssvalue.Type().FieldByName("Version"); ok && pp.Type.String() == "string" && pp.Tag == "" { // tagged, it is a flag
+			versions = append(versions, ssvalue.FieldByName("Version"))
+		}
+	}
+	if len(versions) > 0 {
+		version := versions[0].String() // e.g. set from a variable given by -ldflags=-X
+		if version == "" {
+			version = buildVersion()
+		}
+		for _, vv := range versions {
+			vv.SetString(version)
+		}
+		if flags.Lookup("version") == nil {
+			flags.Var(versionValue{p, version}, "version", " <--print the version and exit")
 		}
 	}
 
-	if pp, ok := sstype.FieldByName("Usage"); ok {
-		vv := ssvalue.FieldByName("Usage")
-		vv.SetString("\n Usage of " + progname + " # " + (string)(pp.Tag) + "\n ARGS:" + moreusage)
+	for _, ssvalue := range p.roots {
+		if pp, ok := ssvalue.Type().FieldByName("Usage"); ok {
+			vv := ssvalue.FieldByName("Usage")
+			vv.SetString("\n Usage of " + progname + " # " + (string)(pp.Tag) + "\n ARGS:" + moreusage)
+		}
 	}
 
 	if hasBoolArg && !_permitStandaloneBool {
@@ -206,37 +987,94 @@ func parseInternal(ss interface{}, _permitStandaloneBool bool) {
 		}
 	}
 
-	flags.Parse(args)
-	if argsiface != nil {
-		*argsiface.(*[]string) = make([]string, len(flags.Args()))
-		copy(*argsiface.(*[]string), flags.Args())
+	p.argv = args
+	args = p.checkFlags(args, plans)
+	if err := flags.Parse(args); err != nil { // only with a ContinueOnError Parser.FlagSet, which reported it
+		panic(err)
+	}
+	if p.err != nil {
+		panic(p.err)
+	}
+	for _, argsiface := range argsifaces {
+		*argsiface = make([]string, len(flags.Args()))
+		copy(*argsiface, flags.Args())
 	}
 
-	flags.Visit(noteVisited) // note all the visited flags, needed below
+	flags.Visit(p.noteVisited)
+	if p.Prompt && !p.reloading {
+		if err := p.prompt(); err != nil {
+			panic(err)
+		}
+	}
+	for root, ssvalue := range p.roots {
+		if pp, ok := ssvalue.Type().FieldByName("Set"); ok && pp.Type.String() == "map[string]bool" {
+			set := map[string]bool{}
+			for _, flagname := range p.order {
+				if p.visited[flagname] && p.fields[flagname].root == root {
+					set[flagname] = true
+				}
+			}
+			ssvalue.FieldByName("Set").Set(reflect.ValueOf(set))
+		}
+	}
 
-	// Set all pointer-type flags that actually had values set
-	for flagname := range pointers {
-		if visited[flagname] {
-			fieldname := flagname
-			if flagname[:1] != strings.ToUpper(flagname[:1]) {
-				fieldname = strings.ToUpper(flagname[:1]) + flagname[1:] + "_"
+	if err := p.validate(); err != nil {
+		panic(err)
+	}
+	for _, ssvalue := range p.roots {
+		if vv, ok := ssvalue.Addr().Interface().(Validator); ok {
+			if err := vv.Validate(); err != nil {
+				panic(err)
 			}
-			pp, _ := sstype.FieldByName(fieldname)
-			vv := ssvalue.FieldByName(fieldname)
-			switch pp.Type.String() {
-			case "*string":
-				vv.Set(reflect.ValueOf(pointers[flagname]))
-			case "*int":
-				vv.Set(reflect.ValueOf(pointers[flagname]))
-			case "*bool":
-				vv.Set(reflect.ValueOf(pointers[flagname]))
-			case "*int64":
-				vv.Set(reflect.ValueOf(pointers[flagname]))
-			case "*float64":
-				vv.Set(reflect.ValueOf(pointers[flagname]))
-			default:
-				continue
+		}
+	}
+	if p.reloading {
+		return
+	}
+	for _, flagname := range p.order {
+		if p.sources[flagname] != SourceUnset {
+			if err := p.hook(p.fields[flagname]); err != nil {
+				panic(err)
+			}
+		}
+	}
+	for _, ssvalue := range p.roots {
+		if vv, ok := ssvalue.Addr().Interface().(AfterParser); ok {
+			if err := vv.AfterParse(); err != nil {
+				panic(err)
 			}
 		}
 	}
 }
+
+// Validator is implemented by options structs that check their values once Parse has set them and checked the tag options.
+type Validator interface {
+	Validate() error
+}
+
+// AfterParser is implemented by options structs that derive members or cause side effects once Parse has set and validated them.
+type AfterParser interface {
+	AfterParse() error
+}
+
+var hookType = reflect.TypeOf((func() error)(nil))
+
+// hook calls the method named by the [hook=Method] option of ff, on the struct holding the member.
+func (p *Parser) hook(ff field) error {
+	name, ok := ff.opts["hook"]
+	if !ok {
+		return nil
+	}
+	holder := p.target(field{root: ff.root, index: ff.index[:len(ff.index)-1]}, false)
+	if holder.Kind() != reflect.Ptr {
+		holder = holder.Addr()
+	}
+	method := holder.MethodByName(name)
+	if !method.IsValid() || method.Type() != hookType {
+		return fmt.Errorf("sflag: hook=%s of member %s needs method %s() error of %s", name, ff.name, name, holder.Type())
+	}
+	if err := method.Interface().(func() error)(); err != nil {
+		return fmt.Errorf("flag -%s: %v", ff.flagname, err)
+	}
+	return nil
+}
@@ -1,8 +1,21 @@
 package sflag
 
 import (
+	"bytes"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 // TestSflag_1 is minimal
@@ -196,3 +209,1200 @@ func TestSflag_9(t *testing.T) {
 		t.Fail()
 	}
 }
+
+// TestSflag_10 shows how to find out where each value came from
+func TestSflag_10(t *testing.T) {
+	var opt = struct {
+		Workers int    "number of workers | 4"
+		Host    string "server to talk to | localhost"
+		Retries int    "no default here"
+		Bar     *int   "bar"
+		Args    []string
+	}{Args: []string{"--Workers=8", "--Bar", "3"}}
+	p := Parse(&opt)
+	p.Dump(os.Stdout)
+
+	if p.Source("Workers") != SourceCommandLine ||
+		p.Source("Host") != SourceDefault ||
+		p.Source("Retries") != SourceUnset ||
+		p.Source("Bar") != SourceCommandLine ||
+		p.Source("NoSuchFlag") != SourceUnset {
+		t.Fail()
+	}
+
+	var buf bytes.Buffer
+	p.Dump(&buf)
+	if !strings.Contains(buf.String(), "--Workers=8\t# commandline\n") || !strings.Contains(buf.String(), "--Host=localhost\t# default\n") {
+		t.Fail()
+	}
+}
+
+type argsOpt struct {
+	Name    string   "a name | anon"
+	Iq_     int      "rendered as --iq | 42"
+	Age     int64    "in ms | 7"
+	GDP     float64  "in Dong | 1.5"
+	Verbose bool     "chatty | false"
+	Bar     *int     "bar"
+	Peers   []string "other servers | a,b"
+	Args    []string
+}
+
+// TestSflag_11 shows how to render a struct back into commandline flags, e.g. to spawn a child with the same options
+func TestSflag_11(t *testing.T) {
+	bar := 3
+	opt := argsOpt{Name: "x=y", Iq_: 42, Age: 9, GDP: 1.5, Bar: &bar, Peers: []string{"x,y", "z"}, Args: []string{"-notaflag"}}
+	args := Args(&opt)
+	fmt.Println("Args =", args)
+	if strings.Join(args, " ") != `--Name=x=y --iq=42 --Age=9 --GDP=1.5 --Verbose=false --Bar=3 --Peers="x,y",z -- -notaflag` {
+		t.Fail()
+	}
+
+	nondef := ArgsNonDefault(&opt)
+	fmt.Println("ArgsNonDefault =", nondef)
+	if strings.Join(nondef, " ") != `--Name=x=y --Age=9 --Bar=3 --Peers="x,y",z -- -notaflag` {
+		t.Fail()
+	}
+
+	child := argsOpt{Args: []string{"--GDP=2", "--Name", "bob"}}
+	p := Parse(&child)
+	set := p.Args()
+	fmt.Println("Parser.Args =", set)
+	if strings.Join(set, " ") != "--Name=bob --GDP=2" {
+		t.Fail()
+	}
+}
+
+// FuzzSflag_Args checks that parsing the output of Args reproduces the original struct
+func FuzzSflag_Args(f *testing.F) {
+	f.Add("anon", 42, int64(7), 1.5, false, true, 0, "", "a")
+	f.Add("", -1, int64(-1<<63), -0.0, true, false, 5, "--Name=evil", "")
+	f.Add("a | b = c", 0, int64(0), 1e300, true, true, -3, "x", ` "x,y" `)
+	f.Fuzz(func(t *testing.T, name string, iq int, age int64, gdp float64, verbose bool, hasBar bool, bar int, arg string, peer string) {
+		if gdp != gdp {
+			t.Skip("NaN never compares equal")
+		}
+		in := argsOpt{Name: name, Iq_: iq, Age: age, GDP: gdp, Verbose: verbose, Peers: []string{peer, name}}
+		if hasBar {
+			in.Bar = &bar
+		}
+		if arg != "" {
+			in.Args = []string{arg}
+		}
+
+		for _, args := range [][]string{Args(&in), ArgsNonDefault(&in)} {
+			out := argsOpt{Args: append([]string{"--Age", strconv.FormatInt(age, 10)}, args...)} // never empty, else os.Args is parsed
+			Parse(&out)
+			if out.Name != in.Name || out.Iq_ != in.Iq_ || out.Age != in.Age || out.GDP != in.GDP || out.Verbose != in.Verbose ||
+				(out.Bar == nil) != (in.Bar == nil) || (out.Bar != nil && *out.Bar != *in.Bar) || !reflect.DeepEqual(out.Peers, in.Peers) ||
+				len(out.Args) != len(in.Args) || (len(in.Args) > 0 && out.Args[0] != in.Args[0]) {
+				t.Fatalf("round trip of %q gave %+v, want %+v", args, out, in)
+			}
+		}
+	})
+}
+
+// TestSflag_12 shows how to log the effective options, with secrets masked
+func TestSflag_12(t *testing.T) {
+	var opt = struct {
+		SomeFile   string  "contains the something | /dev/null"
+		Workers    int     "number of workers      | 4"
+		Ratio      float64 "of something           | 0.5"
+		DBPassword string  "to the database [secret] | hunter2"
+		Bar        *int    "bar"
+		Args       []string
+	}{Args: []string{"--SomeFile", "/tmp/a b"}}
+	Parse(&opt)
+
+	var kv, env, js bytes.Buffer
+	if Encode(&kv, &opt, FormatKeyValue) != nil || Encode(&env, &opt, FormatEnv) != nil || Encode(&js, &opt, FormatJSON) != nil {
+		t.Fail()
+	}
+	fmt.Print(kv.String(), env.String(), js.String())
+
+	if kv.String() != "SomeFile=/tmp/a b\nWorkers=4\nRatio=0.5\nDBPassword=<redacted>\n" {
+		t.Fail()
+	}
+	if env.String() != "SOME_FILE='/tmp/a b'\nWORKERS=4\nRATIO=0.5\nDB_PASSWORD='<redacted>'\n" {
+		t.Fail()
+	}
+	if js.String() != "{\n  \"SomeFile\": \"/tmp/a b\",\n  \"Workers\": 4,\n  \"Ratio\": 0.5,\n  \"DBPassword\": \"<redacted>\",\n  \"Bar\": null\n}\n" {
+		t.Fail()
+	}
+}
+
+// TestSflag_13 shows secret members and reading values from files, e.g. --DBPassword-file=/run/secrets/db
+func TestSflag_13(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(dir+"/pw", []byte("s3cret\n"), 0600)
+	os.WriteFile(dir+"/port", []byte("5432\n"), 0600)
+	os.WriteFile(dir+"/bool", []byte("true"), 0600)
+
+	var opt = struct {
+		Usage      string "demonstrates secrets"
+		DBPassword string "to the database [secret] | hunter2"
+		DBPort     int    "port of the database     | 1"
+		Verbose    bool   "chatty                   | false"
+		Bar        *int   "bar"
+		Args       []string
+	}{Args: []string{"--DBPassword-file=" + dir + "/pw", "--DBPort=@file:" + dir + "/port", "--Verbose-file", dir + "/bool", "--Bar-file", dir + "/port"}}
+	p := Parse(&opt)
+	fmt.Println(opt.Usage)
+	p.Dump(os.Stdout)
+
+	if opt.DBPassword != "s3cret" || opt.DBPort != 5432 || !opt.Verbose || opt.Bar == nil || *opt.Bar != 5432 {
+		t.Fail()
+	}
+	if strings.Contains(opt.Usage, "hunter2") || p.Source("DBPassword") != SourceFile || p.Source("Bar") != SourceFile {
+		t.Fail()
+	}
+	var buf bytes.Buffer
+	p.Dump(&buf)
+	if strings.Contains(buf.String(), "s3cret") {
+		t.Fail()
+	}
+
+	var opt2 = struct {
+		Pin  int "numeric secret [secret] | 0"
+		Args []string
+	}{Args: []string{"--Pin=12x4"}}
+	func() {
+		defer func() {
+			err := recover()
+			fmt.Println("Recovered:", err)
+			if err == nil || strings.Contains(fmt.Sprint(err), "12x4") {
+				t.Fail()
+			}
+		}()
+		Parse(&opt2)
+	}()
+}
+
+// TestSflag_14 shows the required, min, max and enum options, and exporting them as a JSON Schema
+func TestSflag_14(t *testing.T) {
+	type options struct {
+		Usage   string  "schema demonstrator"
+		Host    string  "server to talk to [required]"
+		Workers int     "number of workers [min=1,max=64] | 4"
+		Mode    string  "how to run [enum=fast/safe]      | safe"
+		Ratio   float64 "of something                    | 0.5"
+		Token   string  "api token [secret]               | xyzzy"
+		Args    []string
+	}
+	opt := options{Args: []string{"--Host=example.com", "--Workers=8"}}
+	Parse(&opt)
+	if opt.Host != "example.com" || opt.Workers != 8 || opt.Mode != "safe" {
+		t.Fail()
+	}
+
+	for _, args := range [][]string{{"--Workers=8"}, {"--Host=a", "--Workers=0"}, {"--Host=a", "--Mode=slow"}} {
+		func() {
+			defer func() {
+				err := recover()
+				fmt.Println("Recovered:", err)
+				if err == nil {
+					t.Fail()
+				}
+			}()
+			Parse(&options{Args: args})
+		}()
+	}
+
+	schema, err := Schema(&opt)
+	fmt.Println(string(schema))
+	var doc struct {
+		Description string
+		Properties  map[string]map[string]interface{}
+		Required    []string
+	}
+	if err != nil || json.Unmarshal(schema, &doc) != nil {
+		t.Fatal(err)
+	}
+	if doc.Description != "schema demonstrator" || doc.Properties["Host"]["description"] != "server to talk to" ||
+		len(doc.Required) != 1 || doc.Required[0] != "Host" ||
+		doc.Properties["Workers"]["type"] != "integer" || doc.Properties["Workers"]["default"] != 4.0 ||
+		doc.Properties["Workers"]["minimum"] != 1.0 || doc.Properties["Workers"]["maximum"] != 64.0 ||
+		fmt.Sprint(doc.Properties["Mode"]["enum"]) != "[fast safe]" || doc.Properties["Ratio"]["type"] != "number" ||
+		doc.Properties["Token"]["default"] != nil || doc.Properties["Token"]["writeOnly"] != true {
+		t.Fail()
+	}
+}
+
+type benchOpt struct {
+	Usage       string  "sflags benchmark"
+	SomeFile    string  "contains the something      | /dev/null"
+	IQ          int     "do not inflate              | 42"
+	GDP         float64 "in Vietnamese Dong          | 42000000000000000000000000.0"
+	Age         int64   "in milliseconds since epoch | 42000000000000"
+	SomeCommand string  "! is command that might contain pipe char ! 'yes | head'"
+	Verbose     bool    "chatty                      | false"
+	Workers     int     "number of workers [min=1]   | 4"
+	Bar         *int    "bar"
+	Args        []string
+}
+
+// BenchmarkSflag_Parse parses the same struct type repeatedly, reusing the cached plan
+func BenchmarkSflag_Parse(b *testing.B) {
+	for ii := 0; ii < b.N; ii++ {
+		Parse(&benchOpt{Args: []string{"--Age", "10", "--Bar", "7", "hello"}})
+	}
+}
+
+// BenchmarkSflag_ParseUncached is BenchmarkSflag_Parse with the plan recompiled on every call, as before plans were cached
+func BenchmarkSflag_ParseUncached(b *testing.B) {
+	sstype := reflect.TypeOf(benchOpt{})
+	for ii := 0; ii < b.N; ii++ {
+		plans.Delete(planKey{sstype, NamingVerbatim})
+		Parse(&benchOpt{Args: []string{"--Age", "10", "--Bar", "7", "hello"}})
+	}
+}
+
+// TestSflag_15 shows how to find out about tagged members that sflag cannot bind
+func TestSflag_15(t *testing.T) {
+	type options struct {
+		Workers int            "number of workers | 4"
+		Weights map[string]int "per-peer weights"
+		iq      int            "forgot the underscore | 1"
+		Args    []string
+	}
+
+	var warnings []string
+	p := &Parser{Warn: func(msg string) { warnings = append(warnings, msg) }}
+	opt := options{Args: []string{"--Workers=8"}}
+	p.Parse(&opt)
+	fmt.Println(strings.Join(warnings, "\n"))
+	if opt.Workers != 8 || len(warnings) != 2 || !strings.Contains(warnings[1], "name it Iq_ to get flag --iq") {
+		t.Fail()
+	}
+
+	func() {
+		defer func() {
+			err := recover()
+			fmt.Println("Recovered:", err)
+			if err == nil || !strings.Contains(fmt.Sprint(err), "Weights: unsupported type map[string]int") {
+				t.Fail()
+			}
+		}()
+		(&Parser{Strict: true}).Parse(&options{Args: []string{"--Workers=8"}})
+	}()
+}
+
+// TestSflag_16 shows pointer members of every supported type, slices, TextUnmarshaler types and nested structs
+func TestSflag_16(t *testing.T) {
+	type dbOptions struct {
+		Host string "database host | localhost"
+		Port uint16 "database port | 5432"
+	}
+	type options struct {
+		Timeout *time.Duration "how long to wait [default] | 5s"
+		Retries *uint          "retry count | 3"
+		Peers   []string       "other servers | a,b"
+		Weights *[]float64     "per-peer weights"
+		Bind    *net.IP        "address to listen on"
+		Primary dbOptions      "primary database"
+		Replica *dbOptions     "replica database"
+		Spare   *dbOptions     "spare database"
+		Args    []string
+	}
+
+	opt := options{Args: []string{"--Peers=x", "--Peers=y,z", "--Weights=0.5,1.5", "--Bind=10.0.0.1",
+		"--Primary.Port=6000", "--Replica.Host=db2", "--Timeout=1m"}}
+	p := Parse(&opt)
+	p.Dump(os.Stdout)
+	if *opt.Timeout != time.Minute || opt.Retries != nil || !reflect.DeepEqual(opt.Peers, []string{"x", "y", "z"}) {
+		t.Fail()
+	}
+	if !reflect.DeepEqual(*opt.Weights, []float64{0.5, 1.5}) || !opt.Bind.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Fail()
+	}
+	if opt.Primary != (dbOptions{"localhost", 6000}) || *opt.Replica != (dbOptions{"db2", 5432}) || opt.Spare != nil {
+		t.Fail()
+	}
+	if p.Source("Replica.Port") != SourceDefault || p.Source("Spare.Host") != SourceUnset {
+		t.Fail()
+	}
+
+	opt2 := options{Args: []string{"--"}}
+	Parse(&opt2)
+	if *opt2.Timeout != 5*time.Second || !reflect.DeepEqual(opt2.Peers, []string{"a", "b"}) {
+		t.Fail()
+	}
+	opt2.Peers[0] = "changed" // must not leak into the default of the next parse
+
+	again := options{Args: Args(&opt)}
+	Parse(&again)
+	again.Args = opt.Args
+	if !reflect.DeepEqual(again, opt) {
+		fmt.Println(Args(&opt))
+		t.Fail()
+	}
+	opt3 := options{Args: []string{"--"}}
+	Parse(&opt3)
+	if opt3.Peers[0] != "a" {
+		t.Fail()
+	}
+}
+
+// TestSflag_17 shows how to tell flags given on the commandline from defaulted ones, without pointer members
+func TestSflag_17(t *testing.T) {
+	type options struct {
+		Workers int    "number of workers | 4"
+		Host    string "server to talk to | localhost"
+		Iq_     int    "lower-case flag   | 42"
+		Args    []string
+		Set     map[string]bool
+	}
+
+	opt := options{Args: []string{"--Workers=4", "--iq=7"}}
+	p := Parse(&opt)
+	fmt.Println(opt.Set)
+	if !p.IsSet("Workers") || p.IsSet("Host") || !p.IsSet("iq") || !p.IsSet("Iq_") {
+		t.Fail()
+	}
+	if !reflect.DeepEqual(opt.Set, map[string]bool{"Workers": true, "iq": true}) || opt.Workers != 4 {
+		t.Fail()
+	}
+}
+
+// TestSflag_18 shows counter flags, which count how often they are given
+func TestSflag_18(t *testing.T) {
+	type options struct {
+		V_      int   "verbosity [count] | 0"
+		Debug   *uint "debug level [count]"
+		Retries int   "retry count [count] | 1"
+		Args    []string
+	}
+
+	opt := options{Args: []string{"-v", "-v", "--Debug", "-v", "--Retries", "rest"}}
+	Parse(&opt)
+	if opt.V_ != 3 || *opt.Debug != 1 || opt.Retries != 2 || !reflect.DeepEqual(opt.Args, []string{"rest"}) {
+		fmt.Println(opt.V_, opt.Retries, opt.Args)
+		t.Fail()
+	}
+
+	opt = options{Args: []string{"-v", "--v=5", "-v", "--Retries=false"}}
+	Parse(&opt)
+	if opt.V_ != 6 || opt.Debug != nil || opt.Retries != 0 {
+		t.Fail()
+	}
+}
+
+// TestSflag_19 shows deprecated aliases of renamed flags
+func TestSflag_19(t *testing.T) {
+	type options struct {
+		Output  string "where to write [alias=OutData/out]         | /dev/stdout"
+		Verbose bool   "chatty [alias=Chatty,until=2999-01-01]     | false"
+		Input   string "where to read [alias=InData,until=2001-01-01] | /dev/stdin"
+		Args    []string
+	}
+
+	var warnings []string
+	p := &Parser{Warn: func(msg string) { warnings = append(warnings, msg) }}
+	opt := options{Args: []string{"--OutData=/tmp/x", "--Chatty"}}
+	p.Parse(&opt)
+	fmt.Println(strings.Join(warnings, "\n"))
+	if opt.Output != "/tmp/x" || !opt.Verbose || !p.IsSet("Output") || len(warnings) != 2 || !strings.Contains(warnings[1], "stops working on 2999-01-01") {
+		t.Fail()
+	}
+	if p.Reload() != nil || p.Reload() != nil || len(warnings) != 2 { // without repeating the warnings
+		t.Errorf("got %q", warnings)
+	}
+
+	var usage bytes.Buffer
+	p.flags.SetOutput(&usage)
+	p.printUsage(false)
+	if !strings.Contains(usage.String(), "-Output") || strings.Contains(usage.String(), "OutData") || strings.Contains(usage.String(), "Chatty") {
+		fmt.Println(usage.String())
+		t.Fail()
+	}
+
+	func() {
+		defer func() {
+			err := recover()
+			fmt.Println("Recovered:", err)
+			if err == nil || fmt.Sprint(err) != "flag -InData was removed on 2001-01-01, use -Input" {
+				t.Fail()
+			}
+		}()
+		p.Parse(&options{Args: []string{"--InData=secret.txt"}})
+	}()
+}
+
+// TestSflag_20 shows hidden flags, which parse as usual but stay out of the usage unless asked for
+func TestSflag_20(t *testing.T) {
+	type options struct {
+		Usage     string "demonstrator"
+		Workers   int    "number of workers                  | 4"
+		DebugPort int    "port of the debug server [hidden]  | 6060"
+		Args      []string
+	}
+
+	opt := options{Args: []string{"--DebugPort=7070"}}
+	p := Parse(&opt)
+	if opt.DebugPort != 7070 || !strings.Contains(opt.Usage, "--Workers") || strings.Contains(opt.Usage, "DebugPort") {
+		t.Fail()
+	}
+	var usage bytes.Buffer
+	p.flags.SetOutput(&usage)
+	p.printUsage(false)
+	if strings.Contains(usage.String(), "DebugPort") || !strings.Contains(usage.String(), "-help-all") {
+		t.Fail()
+	}
+
+	if schema, err := Schema(&opt); err != nil || strings.Contains(string(schema), "DebugPort") || !strings.Contains(string(schema), "Workers") {
+		t.Errorf("got %s, %v", schema, err)
+	}
+
+	opt = options{Args: []string{"--Workers=2"}}
+	(&Parser{All: true}).Parse(&opt)
+	if !strings.Contains(opt.Usage, "--DebugPort: 6060") {
+		t.Fail()
+	}
+
+	func() {
+		defer func() {
+			if err := recover(); err != flag.ErrHelp {
+				fmt.Println("Recovered:", err)
+				t.Fail()
+			}
+		}()
+		Parse(&options{Args: []string{"--help-all"}}) // prints all flags to stderr
+	}()
+
+	type plain struct {
+		Workers int "number of workers | 4"
+		Args    []string
+	}
+	if Parse(&plain{Args: []string{"--"}}).flags.Lookup("help-all") != nil { // only defined if something is hidden
+		t.Fail()
+	}
+}
+
+// TestSflag_21 shows sizes, counts and percentages with human units
+func TestSflag_21(t *testing.T) {
+	type options struct {
+		CacheBytes ByteSize      "cache size [max=1GiB] | 512KiB"
+		BufBytes   ByteSize      "buffer size           | 1.5GB"
+		Items      Quantity      "items to process      | 10k"
+		Fill       Percent       "target fill           | 75%"
+		Timeout    time.Duration "give up after [min=1s] | 5s"
+		Args       []string
+	}
+
+	opt := options{Args: []string{"--"}}
+	Parse(&opt)
+	if opt.CacheBytes != 512<<10 || opt.BufBytes != 1500000000 || opt.Items != 10000 || opt.Fill != 0.75 {
+		t.Fail()
+	}
+	fmt.Println(Args(&opt))
+	if !reflect.DeepEqual(Args(&opt), []string{"--CacheBytes=512KiB", "--BufBytes=1.5GB", "--Items=10k", "--Fill=75%", "--Timeout=5s"}) {
+		t.Fail()
+	}
+
+	opt = options{Args: []string{"--CacheBytes=10M", "--BufBytes=4096", "--Items=2.5M", "--Fill=0.5"}}
+	Parse(&opt)
+	if opt.CacheBytes != 10<<20 || opt.BufBytes != 4096 || opt.Items != 2500000 || opt.Fill != 0.5 {
+		t.Fail()
+	}
+
+	for _, args := range [][]string{{"--CacheBytes=2GiB"}, {"--CacheBytes=12XB"}, {"--Timeout=10ms"}} {
+		func() {
+			defer func() {
+				err := recover()
+				fmt.Println("Recovered:", err)
+				if err == nil {
+					t.Fail()
+				}
+			}()
+			Parse(&options{Args: args})
+		}()
+	}
+
+	schema, err := Schema(&opt)
+	if err != nil || !strings.Contains(string(schema), `"cache size (at most 1GiB)"`) || !strings.Contains(string(schema), `"give up after (at least 1s)"`) ||
+		strings.Contains(string(schema), `"maximum"`) || strings.Contains(string(schema), `"minimum"`) {
+		t.Errorf("got %s, %v", schema, err)
+	}
+	for _, fill := range []Percent{1.0 / 3, 0.07, 0.123456789} {
+		in := options{Fill: fill, Timeout: time.Second}
+		out := options{Args: Args(&in)}
+		Parse(&out)
+		if out.Fill != fill {
+			t.Errorf("%v: got %v", Args(&in), out.Fill)
+		}
+	}
+}
+
+// TestSflag_22 shows network addresses, networks and URLs, which are checked as they are parsed
+func TestSflag_22(t *testing.T) {
+	type options struct {
+		Bind     netip.AddrPort "address to listen on     | 127.0.0.1:8080"
+		Peers    []netip.Addr   "other servers            | 10.0.0.2,10.0.0.3"
+		Gateway  net.IP         "default route"
+		Allow    []net.IPNet    "networks allowed to connect | 10.0.0.0/8,192.168.0.0/16"
+		Prefix   netip.Prefix   "network to serve         | fd00::/8"
+		Upstream *url.URL       "where to forward to"
+		Args     []string
+	}
+
+	opt := options{Args: []string{"--Gateway=10.0.0.1", "--Upstream=https://example.com/api", "--Peers=::1"}}
+	Parse(&opt)
+	if opt.Bind.Port() != 8080 || !reflect.DeepEqual(opt.Peers, []netip.Addr{netip.MustParseAddr("::1")}) || !opt.Gateway.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Fail()
+	}
+	if len(opt.Allow) != 2 || !opt.Allow[1].Contains(net.IPv4(192, 168, 1, 1)) || opt.Prefix.Bits() != 8 || opt.Upstream.Host != "example.com" {
+		t.Fail()
+	}
+	fmt.Println(Args(&opt))
+	again := options{Args: Args(&opt)}
+	Parse(&again)
+	if again.Upstream.String() != opt.Upstream.String() || again.Allow[0].String() != "10.0.0.0/8" || again.Bind != opt.Bind {
+		t.Fail()
+	}
+
+	for _, args := range [][]string{{"--Bind=localhost"}, {"--Gateway=10.0.0.256"}, {"--Allow=10.0.0.0"}, {"--Upstream=example.com"}} {
+		func() {
+			defer func() {
+				err := recover()
+				fmt.Println("Recovered:", err)
+				if err == nil {
+					t.Fail()
+				}
+			}()
+			Parse(&options{Args: args})
+		}()
+	}
+}
+
+type testMode string
+
+const (
+	modeFast testMode = "fast"
+	modeSlow testMode = "slow"
+)
+
+type testLevel int
+
+func (lv testLevel) String() string { return [...]string{"low", "medium", "high"}[lv] }
+
+// TestSflag_23 shows enum members of named types, whose values are registered
+func TestSflag_23(t *testing.T) {
+	RegisterEnum([]testMode{modeFast, modeSlow})
+	RegisterEnum([]testLevel{0, 1, 2})
+	type options struct {
+		Usage  string     "demonstrator"
+		Mode   testMode   "how to run  | fast"
+		Level  testLevel  "loudness    | medium"
+		Stages []testMode "stage modes | slow,FAST"
+		Args   []string
+	}
+
+	opt := options{Args: []string{"--Mode=SLOW", "--Level=High"}}
+	Parse(&opt)
+	if opt.Mode != modeSlow || opt.Level != 2 || !reflect.DeepEqual(opt.Stages, []testMode{modeSlow, modeFast}) {
+		t.Fail()
+	}
+	if !strings.Contains(opt.Usage, "how to run (fast|slow)") || !reflect.DeepEqual(Args(&opt)[:2], []string{"--Mode=slow", "--Level=high"}) {
+		fmt.Println(opt.Usage, Args(&opt))
+		t.Fail()
+	}
+
+	func() {
+		defer func() {
+			err := recover()
+			fmt.Println("Recovered:", err)
+			if fmt.Sprint(err) != `invalid value "fsat" for flag -Mode: must be one of fast, slow, did you mean "fast"?` {
+				t.Fail()
+			}
+		}()
+		Parse(&options{Args: []string{"--Mode=fsat"}})
+	}()
+}
+
+// TestSflag_24 shows the suggestions for mistyped flags
+func TestSflag_24(t *testing.T) {
+	type options struct {
+		Verbose bool   "chatty          | false"
+		Iq_     int    "lower-case flag | 42"
+		Secret  string "debug knob [hidden] | "
+		size    int    "forgot the underscore | 1"
+		Args    []string
+	}
+
+	for args, want := range map[string]string{
+		"--Verbos":      "flag provided but not defined: -Verbos, did you mean -Verbose?",
+		"--verbose":     "flag provided but not defined: -verbose, did you mean -Verbose?",
+		"--Iq=7":        "flag provided but not defined: -Iq, did you mean -iq?",
+		"--size=2":      "flag provided but not defined: -size (member size is not a flag: unexported member, name it Size_ to get flag --size)",
+		"--Secrte":      "flag provided but not defined: -Secrte",
+		"--Iq 7 --Nope": "flag provided but not defined: -Iq, did you mean -iq?",
+		"--iq 7 --Nope": "flag provided but not defined: -Nope",
+		"--iq 7 x --No": "",
+	} {
+		func() {
+			defer func() {
+				err := recover()
+				fmt.Println("Recovered:", err)
+				if (want == "" && err != nil) || (want != "" && fmt.Sprint(err) != want) {
+					t.Errorf("%s: got %v, want %s", args, err, want)
+				}
+			}()
+			Parse(&options{Args: strings.Fields(args)})
+		}()
+	}
+}
+
+// TestSflag_25 shows naming strategies, case-insensitive flags and explicit flag names
+func TestSflag_25(t *testing.T) {
+	type dbOptions struct {
+		MaxConns int "connection limit | 10"
+	}
+	type options struct {
+		SomeFile string    "contains the something | /dev/null"
+		HTTPPort int       "port to serve on       | 80"
+		Verbose_ bool      "chatty                 | false"
+		Input    string    "file to read [name=in] | -"
+		DB       dbOptions "database"
+		Args     []string
+	}
+
+	for naming, want := range map[Naming][]string{
+		NamingVerbatim:   {"SomeFile", "HTTPPort", "verbose", "in", "DB.MaxConns"},
+		NamingLowerCamel: {"someFile", "httpPort", "verbose", "in", "db.maxConns"},
+		NamingKebab:      {"some-file", "http-port", "verbose", "in", "db.max-conns"},
+		NamingSnake:      {"some_file", "http_port", "verbose", "in", "db.max_conns"},
+	} {
+		p := &Parser{Naming: naming}
+		p.Parse(&options{Args: []string{"--"}})
+		if !reflect.DeepEqual(p.order, want) {
+			t.Errorf("naming %d: got %v, want %v", naming, p.order, want)
+		}
+	}
+
+	opt := options{Args: []string{"--some-file=x", "--Http_Port", "8080", "--in=y", "--db.max-conns=5", "rest"}}
+	p := &Parser{Naming: NamingKebab, IgnoreCase: true}
+	p.Parse(&opt)
+	if opt.SomeFile != "x" || opt.HTTPPort != 8080 || opt.Input != "y" || opt.DB.MaxConns != 5 || !reflect.DeepEqual(opt.Args, []string{"rest"}) {
+		t.Fail()
+	}
+	if !reflect.DeepEqual(p.Args(), []string{"--some-file=x", "--http-port=8080", "--in=y", "--db.max-conns=5", "--", "rest"}) {
+		fmt.Println(p.Args())
+		t.Fail()
+	}
+
+	func() {
+		defer func() {
+			err := recover()
+			fmt.Println("Recovered:", err)
+			if fmt.Sprint(err) != "flag provided but not defined: -SomeFile, did you mean -some-file?" {
+				t.Fail()
+			}
+		}()
+		(&Parser{Naming: NamingKebab}).Parse(&options{Args: []string{"--SomeFile=x"}})
+	}()
+}
+
+// TestSflag_26 shows parsing with a FlagSet on which others defined flags too, as libraries do on flag.CommandLine
+func TestSflag_26(t *testing.T) {
+	type options struct {
+		Usage   string "demonstrator"
+		Workers int    "number of workers [alias=Threads] | 4"
+		Args    []string
+	}
+
+	flags := flag.NewFlagSet("demo", flag.PanicOnError)
+	level := flags.Int("v", 0, "log level for V logs")
+	opt := options{Args: []string{"-v=2", "--Workers=8", "rest"}}
+	p := &Parser{FlagSet: flags}
+	p.Parse(&opt)
+	fmt.Println(opt.Usage)
+	if *level != 2 || opt.Workers != 8 || !flags.Parsed() || !reflect.DeepEqual(opt.Args, []string{"rest"}) {
+		t.Fail()
+	}
+	if !strings.Contains(opt.Usage, "--v: 0 <-- Default # log level for V logs") || p.Source("v") != SourceCommandLine {
+		t.Fail()
+	}
+	if flags.Lookup("Workers") == nil || flags.Lookup("Workers-file") == nil {
+		t.Fail()
+	}
+	var usage bytes.Buffer
+	flags.SetOutput(&usage)
+	flags.PrintDefaults() // the way flag.CommandLine prints, listing the alias too
+	if strings.Contains(usage.String(), "panic") || !strings.Contains(usage.String(), "-Threads") {
+		t.Errorf("got %s", usage.String())
+	}
+}
+
+// TestSflag_27 shows parsing the options structs of several packages together, and the panic when two define the same flag
+func TestSflag_27(t *testing.T) {
+	type dbOptions struct {
+		Usage  string "database"
+		DBHost string "database host | localhost"
+		Set    map[string]bool
+	}
+	type httpOptions struct {
+		Usage  string "web server"
+		Listen string "address to listen on | :8080"
+		Args   []string
+	}
+	type options struct {
+		Verbose bool "chatty | false"
+		Args    []string
+	}
+
+	var db dbOptions
+	var web httpOptions
+	opt := options{Args: []string{"--Verbose", "--DBHost=db1", "--Listen=:9090", "rest"}}
+	p := Register(&db, &web)
+	p.Parse(&opt)
+	fmt.Println(web.Usage)
+	if !opt.Verbose || db.DBHost != "db1" || web.Listen != ":9090" {
+		t.Fail()
+	}
+	if !reflect.DeepEqual(opt.Args, []string{"rest"}) || !reflect.DeepEqual(web.Args, []string{"rest"}) {
+		t.Fail()
+	}
+	if !reflect.DeepEqual(db.Set, map[string]bool{"DBHost": true}) || !strings.Contains(db.Usage, "--Listen") || !strings.Contains(web.Usage, "--Verbose") {
+		t.Fail()
+	}
+	if !reflect.DeepEqual(p.Args(), []string{"--Verbose=true", "--DBHost=db1", "--Listen=:9090", "--", "rest"}) {
+		t.Errorf("got %q", p.Args())
+	}
+
+	type otherOptions struct {
+		Host string "other host | localhost"
+	}
+	type moreOptions struct {
+		Host string "more host | localhost"
+		Args []string
+	}
+	defer func() {
+		if msg := fmt.Sprint(recover()); !strings.Contains(msg, "flag -Host of sflag.otherOptions.Host conflicts with sflag.moreOptions.Host") {
+			t.Errorf("got %q", msg)
+		}
+	}()
+	Register(&otherOptions{}).Parse(&moreOptions{Args: []string{"--"}})
+}
+
+// TestSflag_28 shows reloading the [reloadable] members from their value files, by hand and when the file changes
+func TestSflag_28(t *testing.T) {
+	type options struct {
+		Level   string "log level [reloadable] | info"
+		Workers int    "number of workers | 4"
+		Args    []string
+	}
+
+	dir := t.TempDir()
+	levelFile, workersFile := dir+"/level", dir+"/workers"
+	os.WriteFile(levelFile, []byte("debug\n"), 0600)
+	os.WriteFile(workersFile, []byte("8\n"), 0600)
+	var opt options
+	opt.Args = []string{"--Level-file=" + levelFile, "--Workers=@file:" + workersFile}
+	changed := make(chan string, 2)
+	p := &Parser{OnReload: func(flagname string, old, new interface{}) { changed <- fmt.Sprint(flagname, ":", old, "->", new) }}
+	p.Parse(&opt)
+	if opt.Level != "debug" || opt.Workers != 8 {
+		t.Fail()
+	}
+
+	os.WriteFile(levelFile, []byte("warn\n"), 0600)
+	os.WriteFile(workersFile, []byte("16\n"), 0600)
+	if err := p.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	p.View(func() {
+		if opt.Level != "warn" || opt.Workers != 8 { // Workers is not reloadable
+			t.Fail()
+		}
+	})
+	if msg := <-changed; msg != "Level:debug->warn" {
+		t.Errorf("got %q", msg)
+	}
+
+	os.WriteFile(workersFile, []byte("x\n"), 0600)
+	if err := p.Reload(); err == nil || opt.Level != "warn" {
+		t.Errorf("got %v", err)
+	}
+
+	os.WriteFile(workersFile, []byte("16\n"), 0600)
+	stop := p.Watch(10 * time.Millisecond)
+	defer stop()
+	os.WriteFile(levelFile, []byte("error\n"), 0600)
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(levelFile, later, later)
+	select {
+	case msg := <-changed:
+		if msg != "Level:warn->error" {
+			t.Errorf("got %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("no reload")
+	}
+}
+
+// testHooked has a [hook] member and implements Validator and AfterParser, for TestSflag_29.
+type testHooked struct {
+	LogFile string "where to log [hook=OpenLog] | -"
+	Min     int    "lowest | 1"
+	Max     int    "highest | 10"
+	Span    int
+	Args    []string
+
+	calls []string
+}
+
+func (th *testHooked) OpenLog() error {
+	th.calls = append(th.calls, "OpenLog "+th.LogFile)
+	if th.LogFile == "/nonexistent" {
+		return fmt.Errorf("cannot open %s", th.LogFile)
+	}
+	return nil
+}
+
+func (th *testHooked) Validate() error {
+	th.calls = append(th.calls, "Validate")
+	if th.Min > th.Max {
+		return fmt.Errorf("Min %d exceeds Max %d", th.Min, th.Max)
+	}
+	return nil
+}
+
+func (th *testHooked) AfterParse() error {
+	th.calls = append(th.calls, "AfterParse")
+	th.Span = th.Max - th.Min
+	return nil
+}
+
+// TestSflag_29 shows the [hook] option and options structs that validate themselves and derive members
+func TestSflag_29(t *testing.T) {
+	opt := testHooked{Args: []string{"--LogFile=app.log", "--Max=5"}}
+	Parse(&opt)
+	if !reflect.DeepEqual(opt.calls, []string{"Validate", "OpenLog app.log", "AfterParse"}) || opt.Span != 4 {
+		t.Errorf("got %q, span %d", opt.calls, opt.Span)
+	}
+
+	for args, want := range map[string]string{
+		"--Min=7 --Max=5":        "Min 7 exceeds Max 5",
+		"--LogFile=/nonexistent": "flag -LogFile: cannot open /nonexistent",
+	} {
+		func() {
+			defer func() {
+				if msg := fmt.Sprint(recover()); msg != want {
+					t.Errorf("%s: got %q", args, msg)
+				}
+			}()
+			Parse(&testHooked{Args: strings.Fields(args)})
+		}()
+	}
+
+	type badHook struct {
+		Level string "log level [hook=Apply] | info"
+		Args  []string
+	}
+	defer func() {
+		if msg := fmt.Sprint(recover()); msg != "sflag: hook=Apply of member Level needs method Apply() error of *sflag.badHook" {
+			t.Errorf("got %q", msg)
+		}
+	}()
+	Parse(&badHook{Args: []string{"--"}})
+}
+
+// TestSflag_30 shows asking for missing [required] values, re-asking until the answer parses
+func TestSflag_30(t *testing.T) {
+	type options struct {
+		Host     string "server to talk to [required]"
+		Port     int    "server port [required] | 5432"
+		Password string "database password [required,secret]"
+		Verbose  bool   "chatty | false"
+		Args     []string
+	}
+
+	var out bytes.Buffer
+	opt := options{Args: []string{"--Verbose"}}
+	p := &Parser{Prompt: true, PromptIn: strings.NewReader("\ndb1\nfive\n\nhunter2\n"), PromptOut: &out}
+	p.Parse(&opt)
+	if opt.Host != "db1" || opt.Port != 5432 || opt.Password != "hunter2" || !opt.Verbose {
+		t.Errorf("got %+v", opt)
+	}
+	if p.Source("Host") != SourcePrompt || !p.IsSet("Port") || p.Source("Verbose") != SourceCommandLine {
+		t.Fail()
+	}
+	if err := p.Reload(); err != nil { // with the answers, without asking again
+		t.Error(err)
+	}
+	want := "Host (server to talk to): a value is required\nHost (server to talk to): " +
+		"Port (server port) [5432]: invalid value \"five\" for flag -Port: parse error\nPort (server port) [5432]: " +
+		"Password (database password): \n"
+	if out.String() != want {
+		t.Errorf("got %q", out.String())
+	}
+
+	defer func() {
+		if msg := fmt.Sprint(recover()); msg != "flag -Host is required (no answer: EOF)" {
+			t.Errorf("got %q", msg)
+		}
+	}()
+	p.PromptIn = strings.NewReader("")
+	p.Parse(&options{Args: []string{"--"}})
+}
+
+// TestSflag_31 shows the Version member, filled from the build info or kept if set, and the --version flag printing it
+func TestSflag_31(t *testing.T) {
+	type options struct {
+		Workers int "number of workers | 4"
+		Version string
+		Args    []string
+	}
+
+	opt := options{Args: []string{"--Workers=8"}}
+	Parse(&opt)
+	if opt.Version == "" || opt.Workers != 8 {
+		t.Errorf("got %+v", opt)
+	}
+
+	stdout := os.Stdout
+	rr, ww, _ := os.Pipe()
+	os.Stdout = ww
+	defer func() {
+		os.Stdout = stdout
+		ww.Close()
+		buf := make([]byte, 100)
+		nn, _ := rr.Read(buf)
+		if msg := string(buf[:nn]); msg != "demo v1.2.3\n" {
+			t.Errorf("got %q", msg)
+		}
+		if err := recover(); err != flag.ErrHelp {
+			t.Errorf("got %v", err)
+		}
+	}()
+	p := &Parser{FlagSet: flag.NewFlagSet("/usr/bin/demo", flag.PanicOnError)}
+	p.Parse(&options{Version: "v1.2.3", Args: []string{"--version", "--Workers=8"}})
+}
+
+// TestSflag_32 shows that IgnoreCase prefers member flags over -file variants, and rejects names that fold to several flags
+func TestSflag_32(t *testing.T) {
+	type options struct {
+		Out     string "output | -"
+		OutFile string "output file | out.txt"
+		Args    []string
+	}
+
+	opt := options{Args: []string{"--outfile=/tmp/out"}}
+	(&Parser{IgnoreCase: true}).Parse(&opt)
+	if opt.OutFile != "/tmp/out" || opt.Out != "-" {
+		t.Errorf("got %+v", opt)
+	}
+
+	type clashing struct {
+		Out  string "output | -"
+		Out_ string "other output | -"
+		Args []string
+	}
+	defer func() {
+		if msg := fmt.Sprint(recover()); msg != "sflag: flag -OUT is ambiguous, it could be -Out or -out" {
+			t.Errorf("got %q", msg)
+		}
+	}()
+	(&Parser{IgnoreCase: true}).Parse(&clashing{Args: []string{"--OUT=x"}})
+}
+
+// TestSflag_33 shows that a member flag wins over the -file variant of another member that the naming gives the same name
+func TestSflag_33(t *testing.T) {
+	type options struct {
+		Some     string "something | x"
+		SomeFile string "some file | y"
+		Args     []string
+	}
+
+	opt := options{Args: []string{"--some-file=z", "--some=@file:/dev/null"}}
+	p := &Parser{Naming: NamingKebab}
+	p.Parse(&opt)
+	if opt.SomeFile != "z" || opt.Some != "" || p.Source("Some") != SourceFile {
+		t.Errorf("got %+v", opt)
+	}
+}
+
+// TestSflag_34 shows that tag defaults are decimal, as they always were, while flag values take base prefixes like the flag package
+func TestSflag_34(t *testing.T) {
+	type options struct {
+		Oct   int    "decimal despite the zero | 010"
+		Mask  uint16 "mask | 0755"
+		Flags []int  "flags | 08,09"
+		Hex   int    "hex on the commandline | 1"
+		Args  []string
+	}
+
+	opt := options{Args: []string{"--Hex=0x10"}}
+	Parse(&opt)
+	if opt.Oct != 10 || opt.Mask != 755 || !reflect.DeepEqual(opt.Flags, []int{8, 9}) || opt.Hex != 16 {
+		t.Errorf("got %+v", opt)
+	}
+}
+
+// TestSflag_35 shows values that start with @file: given with another @, and how Args renders them
+func TestSflag_35(t *testing.T) {
+	type options struct {
+		Name  string "literal name | x"
+		Other string "read from a file | y"
+		Args  []string
+	}
+
+	path := t.TempDir() + "/other"
+	os.WriteFile(path, []byte("@file:/etc/passwd\n"), 0600)
+	opt := options{Args: []string{"--Name=@@file:/tmp/hn", "--Other-file=" + path}}
+	p := Parse(&opt)
+	if opt.Name != "@file:/tmp/hn" || opt.Other != "@file:/etc/passwd" {
+		t.Errorf("got %+v", opt)
+	}
+
+	args := Args(&opt)
+	if !reflect.DeepEqual(args, []string{"--Name=@@file:/tmp/hn", "--Other=@@file:/etc/passwd"}) {
+		t.Errorf("got %q", args)
+	}
+	out := options{Args: args}
+	Parse(&out)
+	if out.Name != opt.Name || out.Other != opt.Other {
+		t.Errorf("got %+v", out)
+	}
+
+	var usage bytes.Buffer
+	p.flags.SetOutput(&usage)
+	p.printUsage(false)
+	if strings.Contains(usage.String(), "-Name-file") || strings.Count(usage.String(), "-file") != 1 {
+		t.Error(usage.String())
+	}
+}
+
+// TestSflag_36 shows asking where values came from while reloads run concurrently
+func TestSflag_36(t *testing.T) {
+	type options struct {
+		Level string "log level [reloadable] | info"
+		Args  []string
+	}
+
+	path := t.TempDir() + "/level"
+	os.WriteFile(path, []byte("debug\n"), 0600)
+	opt := options{Args: []string{"--Level-file=" + path}}
+	p := Parse(&opt)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ii := 0; ii < 100; ii++ {
+			os.WriteFile(path, []byte(fmt.Sprint("level", ii, "\n")), 0600)
+			if err := p.Reload(); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+			if !p.IsSet("Level") {
+				t.Fatal("Level not set")
+			}
+			p.View(func() { p.Dump(io.Discard) })
+		}
+	}
+}
+
+// TestSflag_37 shows that prompting needs a terminal, which /dev/null is not, though it is a char device
+func TestSflag_37(t *testing.T) {
+	type options struct {
+		Host string "server to talk to [required]"
+		Args []string
+	}
+
+	devnull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer devnull.Close()
+	stdin := os.Stdin
+	os.Stdin = devnull
+	defer func() {
+		os.Stdin = stdin
+		if msg := fmt.Sprint(recover()); msg != "flag -Host is required (cannot prompt, stdin is not a terminal)" {
+			t.Errorf("got %q", msg)
+		}
+	}()
+	(&Parser{Prompt: true}).Parse(&options{Args: []string{"--"}})
+}
+
+// TestSflag_38 shows values that Encode quotes, so that sh reads the env-file back verbatim and log lines cannot be faked
+func TestSflag_38(t *testing.T) {
+	var opt = struct {
+		Cmd   string "command to run"
+		Quote string "a quote"
+		Note  string "free text"
+		Args  []string
+	}{Args: []string{"--Cmd=a;b&c<d>(e)", "--Quote=it's", "--Note=x\nWorkers=9\u2028"}}
+	Parse(&opt)
+
+	var kv, env bytes.Buffer
+	Encode(&kv, &opt, FormatKeyValue)
+	Encode(&env, &opt, FormatEnv)
+	if kv.String() != "Cmd=a;b&c<d>(e)\nQuote=it's\nNote=\"x\\nWorkers=9\\u2028\"\n" {
+		t.Errorf("got %q", kv.String())
+	}
+	if env.String() != "CMD='a;b&c<d>(e)'\nQUOTE='it'\\''s'\nNOTE='x\nWorkers=9\u2028'\n" {
+		t.Errorf("got %q", env.String())
+	}
+
+	if sh, err := exec.LookPath("sh"); err == nil {
+		out, err := exec.Command(sh, "-c", env.String()+`printf '%s|%s|%s' "$CMD" "$QUOTE" "$NOTE"`).Output()
+		if err != nil || string(out) != opt.Cmd+"|"+opt.Quote+"|"+opt.Note {
+			t.Errorf("sh read back %q, %v", out, err)
+		}
+	}
+}
+
+// TestSflag_39 shows rendering, encoding and describing flags with the naming of the Parser that parses them
+func TestSflag_39(t *testing.T) {
+	type options struct {
+		SomeFile string "input file | -"
+		MaxConns int    "connection limit [max=100] | 10"
+		Args     []string
+	}
+
+	opt := options{Args: []string{"--some-file=in.txt"}}
+	p := &Parser{Naming: NamingKebab}
+	p.Parse(&opt)
+	args := p.Naming.Args(&opt)
+	if strings.Join(args, " ") != "--some-file=in.txt --max-conns=10" || strings.Join(p.Naming.ArgsNonDefault(&opt), " ") != "--some-file=in.txt" {
+		t.Errorf("got %q", args)
+	}
+	child := options{Args: args}
+	(&Parser{Naming: NamingKebab}).Parse(&child)
+	if child.SomeFile != "in.txt" || child.MaxConns != 10 {
+		t.Errorf("got %+v", child)
+	}
+
+	var kv bytes.Buffer
+	p.Naming.Encode(&kv, &opt, FormatKeyValue)
+	schema, err := p.Naming.Schema(&opt)
+	if kv.String() != "some-file=in.txt\nmax-conns=10\n" || err != nil || !strings.Contains(string(schema), `"max-conns": {`) {
+		t.Errorf("got %q, %s, %v", kv.String(), schema, err)
+	}
+}
+
+// TestSflag_40 shows that Args leaves out [secret] members, which a child should read from a file instead
+func TestSflag_40(t *testing.T) {
+	type options struct {
+		User     string "login name | admin"
+		Password string "login password [secret] | "
+		Args     []string
+	}
+
+	opt := options{User: "bob", Password: "hunter2"}
+	if args := Args(&opt); strings.Join(args, " ") != "--User=bob" {
+		t.Errorf("got %q", args)
+	}
+	p := Parse(&options{Args: []string{"--Password=hunter2", "--User=bob"}})
+	if args := p.Args(); strings.Join(args, " ") != "--User=bob" {
+		t.Errorf("got %q", args)
+	}
+}
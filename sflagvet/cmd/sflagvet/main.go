@@ -0,0 +1,17 @@
+// Command sflagvet checks the struct tags of options structs passed to sflag.Parse and sflag.Parse2.
+// It lives in a module of its own, so that sflag itself does not depend on golang.org/x/tools.
+//
+//	cd sflagvet && go install ./cmd/sflagvet
+//	sflagvet ./...
+//
+// or, as part of go vet,
+//
+//	go vet -vettool=$(which sflagvet) ./...
+package main
+
+import (
+	"github.com/LDCS/sflag/sflagvet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() { singlechecker.Main(sflagvet.Analyzer) }
@@ -0,0 +1,16 @@
+module github.com/LDCS/sflag/sflagvet
+
+go 1.25.0
+
+require (
+	github.com/LDCS/sflag v0.0.0
+	golang.org/x/tools v0.45.0
+)
+
+require (
+	golang.org/x/mod v0.36.0 // indirect
+	golang.org/x/sync v0.20.0 // indirect
+)
+
+// sflagvet is developed against the sflag package next to it.
+replace github.com/LDCS/sflag => ../
@@ -0,0 +1,329 @@
+// Package sflagvet defines an Analyzer that checks the tags of structs passed to sflag.Parse, sflag.Parse2 and sflag.Register,
+// so that tag mistakes are found at vet time rather than at runtime.
+package sflagvet
+
+import (
+	"errors"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/LDCS/sflag"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `check struct tags of options structs passed to sflag.Parse, sflag.Parse2 and sflag.Register
+
+Reports tagged members whose type sflag skips, lower-case members
+without the trailing underscore that sflag needs to set them, defaults that
+do not parse as the member type, tags without the | delineator (whose text
+then is neither description nor default), and bad option values.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "sflagvet",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+const sflagPath = "github.com/LDCS/sflag"
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	checked := map[*types.Struct]bool{}
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(node ast.Node) {
+		call := node.(*ast.CallExpr)
+		name := parseCallName(pass, call)
+		if name == "" || (name != "Register" && len(call.Args) != 1) {
+			return
+		}
+		for _, arg := range call.Args {
+			if tv := pass.TypesInfo.Types[arg]; tv.IsNil() {
+				continue // Parser.Parse of registered structs only
+			}
+			ptr, ok := pass.TypesInfo.TypeOf(arg).Underlying().(*types.Pointer)
+			if !ok {
+				pass.Reportf(arg.Pos(), "sflag.%s needs a pointer to a struct", name)
+				continue
+			}
+			st, ok := ptr.Elem().Underlying().(*types.Struct)
+			if !ok {
+				pass.Reportf(arg.Pos(), "sflag.%s needs a pointer to a struct", name)
+				continue
+			}
+			if checked[st] {
+				continue
+			}
+			checked[st] = true
+			checkStruct(pass, st, arg.Pos(), "", map[*types.Struct]bool{st: true})
+		}
+	})
+	return nil, nil
+}
+
+// parseCallName returns the name of the function that call calls, if it is sflag.Parse, sflag.Parse2 or sflag.Register, or else "".
+func parseCallName(pass *analysis.Pass, call *ast.CallExpr) string {
+	var id *ast.Ident
+	switch fun := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	case *ast.Ident:
+		id = fun
+	default:
+		return ""
+	}
+	fn, ok := pass.TypesInfo.Uses[id].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != sflagPath {
+		return ""
+	}
+	switch fn.Name() {
+	case "Parse", "Parse2", "Register":
+		return fn.Name()
+	}
+	return ""
+}
+
+// checkStruct reports the tag problems of the members of st, whose members are named with prefix.
+// Members declared in other packages are reported at callPos.  outer holds the structs enclosing st, to stop at recursive types.
+func checkStruct(pass *analysis.Pass, st *types.Struct, callPos token.Pos, prefix string, outer map[*types.Struct]bool) {
+	for ii := 0; ii < st.NumFields(); ii++ {
+		fld := st.Field(ii)
+		pos := fld.Pos()
+		if fld.Pkg() != pass.Pkg {
+			pos = callPos
+		}
+		isArgs := fld.Name() == "Args" && types.TypeString(fld.Type(), nil) == "[]string"
+		isSet := fld.Name() == "Set" && types.TypeString(fld.Type(), nil) == "map[string]bool"
+		if fld.Anonymous() || (prefix == "" && (fld.Name() == "Usage" || isArgs || isSet)) {
+			continue // not flags, as in sflag.Parse
+		}
+		tag, ok := sflag.ParseTag(fld.Name(), st.Tag(ii))
+		if !ok {
+			continue
+		}
+		name := prefix + fld.Name()
+
+		if !fld.Exported() {
+			if unicode.IsLower(rune(fld.Name()[0])) {
+				fixed := strings.ToUpper(fld.Name()[:1]) + fld.Name()[1:] + "_"
+				pass.Reportf(pos, "sflag cannot set lower-case member %s: name it %s to get flag --%s", name, fixed, fld.Name())
+			} else {
+				pass.Reportf(pos, "sflag cannot set unexported member %s", name)
+			}
+			continue
+		}
+
+		kind, ok := bindKind(fld.Type())
+		switch {
+		case ok && kind.nested != nil && outer[kind.nested]:
+			pass.Reportf(pos, "sflag does not bind member %s: type %s is recursive", name, types.TypeString(fld.Type(), qualifier(pass)))
+			continue
+		case ok && kind.nested != nil:
+			outer[kind.nested] = true
+			checkStruct(pass, kind.nested, callPos, name+".", outer)
+			delete(outer, kind.nested)
+			continue
+		case !ok:
+			pass.Reportf(pos, "sflag does not bind member %s: type %s is not supported", name, types.TypeString(fld.Type(), qualifier(pass)))
+			continue
+		}
+		shown := types.TypeString(kind.typ, qualifier(pass))
+		if _, ptrDefault := tag.Options["default"]; kind.ptr && !ptrDefault {
+			// the default is not applied
+		} else if !tag.HasDefault {
+			if _, required := tag.Options["required"]; !required {
+				pass.Reportf(pos, "sflag tag of %s has no | delineator: %q is neither shown as description nor used as default", name, tag.Default)
+			}
+		} else if !kind.parses(tag.Default) {
+			pass.Reportf(pos, "sflag default %q of %s does not parse as %s", tag.Default, name, shown)
+		}
+		checkOptions(pass, pos, name, shown, kind, tag)
+	}
+}
+
+// checkOptions reports option values that sflag.Parse would reject or misread.
+func checkOptions(pass *analysis.Pass, pos token.Pos, name, shown string, kind memberKind, tag sflag.Tag) {
+	for _, bound := range []string{"min", "max"} {
+		limit, ok := tag.Options[bound]
+		if !ok {
+			continue
+		}
+		if _, err := strconv.ParseFloat(limit, 64); err != nil && !(kind.units() && kind.parses(limit)) {
+			pass.Reportf(pos, "sflag option %s=%s of %s is not a number", bound, limit, name)
+		} else if kind.list || !kind.numeric() {
+			pass.Reportf(pos, "sflag option %s of %s needs a numeric member", bound, name)
+		}
+	}
+	if date, ok := tag.Options["until"]; ok {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			pass.Reportf(pos, "sflag option until=%s of %s is not a YYYY-MM-DD date", date, name)
+		}
+		if strings.Trim(tag.Options["alias"], " /") == "" {
+			pass.Reportf(pos, "sflag option until of %s has no effect without alias", name)
+		}
+	}
+	if _, ok := tag.Options["count"]; ok && (kind.list || !kind.integer()) {
+		pass.Reportf(pos, "sflag option count of %s needs an integer member", name)
+	}
+	if list, ok := tag.Options["enum"]; ok {
+		elem := kind
+		elem.list = false // the choices apply to each element
+		for _, choice := range strings.Split(list, "/") {
+			if choice = strings.TrimSpace(choice); !elem.parses(choice) {
+				pass.Reportf(pos, "sflag enum value %q of %s does not parse as %s", choice, name, shown)
+			}
+		}
+	}
+}
+
+// memberKind describes how sflag binds a member type.
+type memberKind struct {
+	typ    types.Type    // the member type, without the pointer
+	scalar string        // name of the basic kind of the (element) type, or "duration" or "text"
+	list   bool          // slice of comma-separated values
+	ptr    bool          // pointer to the type
+	nested *types.Struct // struct whose members are flags of their own
+}
+
+// bindKind works out how sflag binds a member of type typ.  It returns false for types sflag.Parse skips.
+func bindKind(typ types.Type) (memberKind, bool) {
+	kind := memberKind{typ: typ}
+	if ptr, ok := typ.(*types.Pointer); ok {
+		kind.typ, kind.ptr = ptr.Elem(), true
+	}
+	if kind.scalar = scalarKind(kind.typ); kind.scalar != "" {
+		return kind, true
+	}
+	if slice, ok := kind.typ.Underlying().(*types.Slice); ok {
+		kind.scalar, kind.list = scalarKind(slice.Elem()), true
+		return kind, kind.scalar != ""
+	}
+	if st, ok := kind.typ.Underlying().(*types.Struct); ok {
+		kind.nested = st
+		return kind, true
+	}
+	return kind, false
+}
+
+// scalarKind returns the name sflag parses a single value of typ as, or "" if it cannot.
+func scalarKind(typ types.Type) string {
+	if types.TypeString(typ, nil) == "time.Duration" {
+		return "duration"
+	}
+	if _, ok := textTypes[types.TypeString(typ, nil)]; ok {
+		return types.TypeString(typ, nil)
+	}
+	if obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(typ), true, nil, "UnmarshalText"); obj != nil {
+		if _, ok := obj.(*types.Func); ok {
+			return "text"
+		}
+	}
+	basic, ok := typ.Underlying().(*types.Basic)
+	if !ok {
+		return ""
+	}
+	switch basic.Kind() {
+	case types.String, types.Bool, types.Int, types.Int8, types.Int16, types.Int32, types.Int64,
+		types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64, types.Float32, types.Float64:
+		return basic.Name()
+	}
+	return ""
+}
+
+// textTypes parse the values of the types sflag sets from text whose syntax is known here, by full type name.
+// net.IPNet and url.URL are among them although they lack an UnmarshalText method, as sflag handles them specially.
+var textTypes = map[string]func(string) error{
+	sflagPath + ".ByteSize": func(s string) error { return new(sflag.ByteSize).UnmarshalText([]byte(s)) },
+	sflagPath + ".Quantity": func(s string) error { return new(sflag.Quantity).UnmarshalText([]byte(s)) },
+	sflagPath + ".Percent":  func(s string) error { return new(sflag.Percent).UnmarshalText([]byte(s)) },
+	"net.IP":                func(s string) error { return new(net.IP).UnmarshalText([]byte(s)) },
+	"net.IPNet":             func(s string) error { _, _, err := net.ParseCIDR(s); return err },
+	"net/netip.Addr":        func(s string) error { _, err := netip.ParseAddr(s); return err },
+	"net/netip.AddrPort":    func(s string) error { _, err := netip.ParseAddrPort(s); return err },
+	"net/netip.Prefix":      func(s string) error { _, err := netip.ParsePrefix(s); return err },
+	"net/url.URL": func(s string) error {
+		uu, err := url.Parse(s)
+		if err == nil && (uu.Scheme == "" || (uu.Host == "" && uu.Opaque == "" && uu.Path == "")) {
+			err = errors.New("not an absolute URL")
+		}
+		return err
+	},
+}
+
+// units reports whether min and max may be given in the syntax of the member, e.g. max=1GiB or min=1s.
+func (kind memberKind) units() bool {
+	return kind.scalar == "duration" || strings.HasPrefix(kind.scalar, sflagPath+".")
+}
+
+func (kind memberKind) numeric() bool {
+	return kind.integer() || strings.HasPrefix(kind.scalar, "float") || kind.units()
+}
+
+func (kind memberKind) integer() bool {
+	return strings.HasPrefix(kind.scalar, "int") || strings.HasPrefix(kind.scalar, "uint")
+}
+
+// parses reports whether def is a valid value of kind, as parsed by sflag.
+func (kind memberKind) parses(def string) bool {
+	if kind.list {
+		if def == "" {
+			return true
+		}
+		elem := kind
+		elem.list = false
+		for _, part := range strings.Split(def, ",") {
+			if !elem.parses(strings.TrimSpace(part)) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var err error
+	switch kind.scalar {
+	case "int", "uint":
+		_, err = parseNum(kind.scalar, def, strconv.IntSize)
+	case "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64":
+		bits, _ := strconv.Atoi(strings.TrimLeftFunc(kind.scalar, unicode.IsLetter))
+		_, err = parseNum(kind.scalar, def, bits)
+	case "float32":
+		_, err = strconv.ParseFloat(def, 32)
+	case "float64":
+		_, err = strconv.ParseFloat(def, 64)
+	case "bool":
+		_, err = strconv.ParseBool(def)
+	case "duration":
+		_, err = time.ParseDuration(def)
+	default:
+		if parse, ok := textTypes[kind.scalar]; ok && def != "" { // empty is the zero value
+			err = parse(def)
+		}
+	}
+	return err == nil
+}
+
+func parseNum(scalar, def string, bits int) (interface{}, error) {
+	if strings.HasPrefix(scalar, "uint") {
+		return strconv.ParseUint(def, 10, bits) // tag numbers are decimal, unlike flag values
+	}
+	return strconv.ParseInt(def, 10, bits)
+}
+
+// qualifier names types of other packages by package name, as in source, e.g. sflag.ByteSize.
+func qualifier(pass *analysis.Pass) types.Qualifier {
+	return func(pkg *types.Package) string {
+		if pkg == pass.Pkg {
+			return ""
+		}
+		return pkg.Name()
+	}
+}
@@ -0,0 +1,12 @@
+package sflagvet_test
+
+import (
+	"testing"
+
+	"github.com/LDCS/sflag/sflagvet"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), sflagvet.Analyzer, "a")
+}
@@ -0,0 +1,83 @@
+package a
+
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"time"
+
+	"github.com/LDCS/sflag"
+)
+
+type Mode string
+
+type options struct {
+	Usage    string "demonstrator"
+	SomeFile string "contains the something | /dev/null"
+	Iq_      int    "lower-case flag | 42"
+	Bar      *int   "bar"
+	Host     string "server to talk to [required]"
+	Args     []string
+	Others   []string
+	Embedded
+
+	Workers  int            "number of workers     | four" // want `sflag default "four" of Workers does not parse as int`
+	Verbose  bool           "chatty                | yes"  // want `sflag default "yes" of Verbose does not parse as bool`
+	Retries  int            "how often to retry"           // want `sflag tag of Retries has no \| delineator`
+	iq       int            "forgot the underscore | 1"    // want `sflag cannot set lower-case member iq: name it Iq_ to get flag --iq`
+	Timeout  time.Duration  "how long to wait      | 5 s"  // want `sflag default "5 s" of Timeout does not parse as time.Duration`
+	Mode     Mode           "how to run            | fast"
+	Ratio    float64        "of something [min=x]  | 0.5"  // want `sflag option min=x of Ratio is not a number`
+	Level    int            "loudness [enum=1/2/loud] | 1" // want `sflag enum value "loud" of Level does not parse as int`
+	Name     string         "who [max=3]           | bob"  // want `sflag option max of Name needs a numeric member`
+	Ports    []uint16       "ports to listen on    | 80,x" // want `sflag default "80,x" of Ports does not parse as \[\]uint16`
+	Wait     *time.Duration "grace period [default] | 1h"
+	Limit    *uint8         "cap [default]         | 300" // want `sflag default "300" of Limit does not parse as uint8`
+	Started  time.Time      "start time            | now"
+	Weights  map[string]int "per-peer weights" // want `sflag does not bind member Weights: type map\[string\]int is not supported`
+	DB       dbOptions      "database"
+	Replica  *dbOptions     "replica database"
+	Chain    *chain         "linked options"
+	Loud     bool           "chatty [count]        | false"                       // want `sflag option count of Loud needs an integer member`
+	Output   string         "where to write [alias=OutData,until=2027-13-01] | -" // want `sflag option until=2027-13-01 of Output is not a YYYY-MM-DD date`
+	Input    string         "where to read [until=2027-01-01] | -"                // want `sflag option until of Input has no effect without alias`
+	OutData  *string        "old name [alias=Out,until=soon]"                     // want `sflag option until=soon of OutData is not a YYYY-MM-DD date`
+	Cache    sflag.ByteSize "cache size [max=1GiB] | 12XB"                        // want `sflag default "12XB" of Cache does not parse as sflag.ByteSize`
+	Grace    time.Duration  "grace [min=1s,max=soon] | 2s"                        // want `sflag option max=soon of Grace is not a number`
+	Bind     netip.AddrPort "address to listen on | localhost"                    // want `sflag default "localhost" of Bind does not parse as netip.AddrPort`
+	Peers    []net.IP       "other servers | 10.0.0.1,x"                          // want `sflag default "10.0.0.1,x" of Peers does not parse as \[\]net.IP`
+	Upstream url.URL        "where to forward | example.com"                      // want `sflag default "example.com" of Upstream does not parse as url.URL`
+	Allow    net.IPNet      "allowed | 10.0.0.0/8"
+	Gateway  *net.IP        "default route"
+	Raw      chan int       "no such flag | " // want `sflag does not bind member Raw: type chan int is not supported`
+}
+
+type Embedded struct{}
+
+type dbOptions struct {
+	Host string "database host | localhost"
+	Port uint16 "database port | 99999" // want `sflag default "99999" of DB.Port does not parse as uint16` `sflag default "99999" of Replica.Port does not parse as uint16`
+}
+
+type chain struct {
+	Name string "link name | x"
+	Next *chain "next link" // want `sflag does not bind member Chain.Next: type \*chain is recursive`
+}
+
+func main() {
+	var opt options
+	sflag.Parse(&opt)
+	sflag.Parse2(&opt) // already reported
+
+	var anon = struct {
+		Count int "how many" // want `sflag tag of Count has no \| delineator`
+	}{}
+	sflag.Parse(&anon)
+
+	sflag.Parse(opt) // want `sflag.Parse needs a pointer to a struct`
+
+	var db struct {
+		Port int "database port | x" // want `sflag default "x" of Port does not parse as int`
+	}
+	sflag.Register(&db, 3).Parse(nil) // want `sflag.Register needs a pointer to a struct`
+}
@@ -0,0 +1,15 @@
+// Package sflag is a stub of the real package, declaring what the analyzer looks for.
+package sflag
+
+type Parser struct{}
+
+func Parse(ss interface{}) *Parser  { return nil }
+func Parse2(ss interface{}) *Parser { return nil }
+
+func Register(ss ...interface{}) *Parser { return nil }
+
+func (p *Parser) Parse(ss interface{}) {}
+
+type ByteSize int64
+
+func (bs *ByteSize) UnmarshalText(text []byte) error { return nil }
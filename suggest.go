@@ -0,0 +1,163 @@
+package sflag
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// didYouMean returns a ", did you mean ...?" hint for the candidate closest to word, or "" if none is close.
+func didYouMean(word string, candidates []string) string {
+	if best := suggest(word, candidates); best != "" {
+		return fmt.Sprintf(", did you mean %q?", best)
+	}
+	return ""
+}
+
+// suggest returns the candidate closest to word by edit distance, ignoring case, if it is close enough to be a likely typo.
+func suggest(word string, candidates []string) string {
+	best, bestDist := "", len(word)/3+2 // allow a third of the word, plus a transposition
+	for _, cand := range candidates {
+		if dist := editDistance(strings.ToLower(word), strings.ToLower(cand)); dist < bestDist {
+			best, bestDist = cand, dist
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between aa and bb.
+func editDistance(aa, bb string) int {
+	ra, rb := []rune(aa), []rune(bb)
+	prev := make([]int, len(rb)+1)
+	for jj := range prev {
+		prev[jj] = jj
+	}
+	for ii := 1; ii <= len(ra); ii++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = ii
+		for jj := 1; jj <= len(rb); jj++ {
+			cost := 1
+			if ra[ii-1] == rb[jj-1] {
+				cost = 0
+			}
+			cur[jj] = prev[jj-1] + cost
+			if del := prev[jj] + 1; del < cur[jj] {
+				cur[jj] = del
+			}
+			if ins := cur[jj-1] + 1; ins < cur[jj] {
+				cur[jj] = ins
+			}
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+// checkFlags fails like the flag package does on the first flag in args that is not defined, but adds a suggestion.
+// With IgnoreCase, it first renames flags that match a defined flag but for case, dashes and underscores.
+// It walks args the way flag.FlagSet.Parse does, leaves syntax errors to it, and returns args with flags renamed.
+func (p *Parser) checkFlags(args []string, plans []*plan) []string {
+	args = append([]string{}, args...)
+	for ii := 0; ii < len(args); ii++ {
+		arg := args[ii]
+		if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+			break
+		}
+		dashes := "-"
+		if arg[1] == '-' {
+			dashes = "--"
+		}
+		name := arg[len(dashes):]
+		if name == "" || name[0] == '-' || name[0] == '=' {
+			break
+		}
+		name, _, hasValue := strings.Cut(name, "=")
+		_flag := p.flags.Lookup(name)
+		if _flag == nil && p.IgnoreCase {
+			if _flag = p.foldedFlag(name); _flag != nil {
+				args[ii] = dashes + _flag.Name + arg[len(dashes)+len(name):]
+			}
+		}
+		switch {
+		case _flag == nil && (name == "help" || name == "h"):
+			return args
+		case _flag == nil:
+			err := fmt.Errorf("flag provided but not defined: -%s%s", name, p.flagHint(name, plans))
+			fmt.Fprintln(p.flags.Output(), err)
+			p.flags.Usage()
+			if p.flags.ErrorHandling() == flag.ExitOnError {
+				os.Exit(2)
+			}
+			panic(err)
+		}
+		if bf, ok := _flag.Value.(interface{ IsBoolFlag() bool }); !hasValue && !(ok && bf.IsBoolFlag()) {
+			ii++ // the value
+		}
+	}
+	return args
+}
+
+// foldedFlag returns the flag whose name folds to the same as name (see foldName), or nil.  Member flags win over
+// -file variants, aliases and flags defined by others, and it panics if name folds to more than one flag of the same kind.
+func (p *Parser) foldedFlag(name string) *flag.Flag {
+	var members, others []*flag.Flag
+	p.flags.VisitAll(func(_flag *flag.Flag) {
+		if foldName(_flag.Name) != foldName(name) {
+			return
+		}
+		if _, ok := p.fields[_flag.Name]; ok {
+			members = append(members, _flag)
+		} else {
+			others = append(others, _flag)
+		}
+	})
+	found := members
+	if len(found) == 0 {
+		found = others
+	}
+	switch len(found) {
+	case 0:
+		return nil
+	case 1:
+		return found[0]
+	}
+	names := make([]string, len(found))
+	for ii, _flag := range found {
+		names[ii] = "-" + _flag.Name
+	}
+	panic(fmt.Sprintf("sflag: flag -%s is ambiguous, it could be %s", name, strings.Join(names, " or ")))
+}
+
+// flagHint returns a hint for the undefined flag name: the flag of the member so named, e.g. -foo for -Foo_,
+// why a member so named is not a flag, or the closest flag name.
+func (p *Parser) flagHint(name string, plans []*plan) string {
+	bare := strings.TrimSuffix(name, "_")
+	for _, flagname := range p.order {
+		if member := strings.TrimSuffix(p.fields[flagname].name, "_"); strings.EqualFold(bare, member) && p.listed(flagname) {
+			return fmt.Sprintf(", did you mean -%s?", flagname)
+		}
+	}
+	for _, pl := range plans {
+		for _, ff := range pl.fields {
+			if !ff.supported && strings.EqualFold(bare, strings.TrimSuffix(ff.name, "_")) {
+				return " (member " + ff.name + " is not a flag: " + ff.skip + ")"
+			}
+		}
+	}
+	var names []string
+	p.flags.VisitAll(func(_flag *flag.Flag) {
+		if p.listed(_flag.Name) {
+			names = append(names, _flag.Name)
+		}
+	})
+	if best := suggest(bare, names); best != "" {
+		return fmt.Sprintf(", did you mean -%s?", best)
+	}
+	return ""
+}
+
+// listed reports whether the flag usage shows flagname.
+func (p *Parser) listed(flagname string) bool {
+	return !p.aliases[flagname] && !p.twin(flagname) && (p.All || !p.hidden[flagname])
+}
@@ -0,0 +1,7 @@
+//go:build darwin || freebsd
+
+package sflag
+
+import "syscall"
+
+const ioctlGetTermios, ioctlSetTermios = syscall.TIOCGETA, syscall.TIOCSETA
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd
+
+package sflag
+
+import "errors"
+
+// isTerminal reports whether fd is a terminal, which sflag cannot tell on this system.
+func isTerminal(fd uintptr) bool { return false }
+
+// hideInput fails, as sflag cannot turn off the echo of a terminal on this system.
+func hideInput(fd uintptr) (func(), error) {
+	return nil, errors.New("cannot turn off the echo on this system")
+}
@@ -0,0 +1,122 @@
+package sflag
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a number of bytes, which flags and tag defaults accept with a unit, e.g. 512KiB, 1.5GB or 10M.
+// KiB, MiB, GiB, TiB, PiB, EiB and the bare letters K, M, G, T, P, E are powers of 1024, while KB, MB, GB, TB, PB, EB are powers of 1000.
+// Units are case-insensitive, and a number without unit counts bytes.  Values print with the largest unit that keeps them exact.
+type ByteSize int64
+
+// Quantity is a count, which flags and tag defaults accept with an SI suffix, e.g. 10k, 2.5M or 1G (k, M, G, T, P, E, case-insensitive).
+type Quantity int64
+
+// Percent is a fraction, which flags and tag defaults accept either as such, e.g. 0.75, or as a percentage, e.g. 75%.
+type Percent float64
+
+var (
+	byteUnits = []unit{
+		{"EiB", 1 << 60}, {"EB", 1e18}, {"PiB", 1 << 50}, {"PB", 1e15}, {"TiB", 1 << 40}, {"TB", 1e12},
+		{"GiB", 1 << 30}, {"GB", 1e9}, {"MiB", 1 << 20}, {"MB", 1e6}, {"KiB", 1 << 10}, {"KB", 1e3},
+		{"E", 1 << 60}, {"P", 1 << 50}, {"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	}
+	siUnits = []unit{{"E", 1e18}, {"P", 1e15}, {"T", 1e12}, {"G", 1e9}, {"M", 1e6}, {"k", 1e3}}
+)
+
+// unit is a suffix and the multiplier it stands for.
+type unit struct {
+	name string
+	mult float64
+}
+
+func (bs *ByteSize) UnmarshalText(text []byte) error {
+	num, err := parseUnits(string(text), byteUnits)
+	*bs = ByteSize(num)
+	return err
+}
+
+func (bs ByteSize) MarshalText() ([]byte, error) {
+	return []byte(formatUnits(int64(bs), byteUnits[:12], "B")), nil
+}
+
+func (qq *Quantity) UnmarshalText(text []byte) error {
+	num, err := parseUnits(string(text), siUnits)
+	*qq = Quantity(num)
+	return err
+}
+
+func (qq Quantity) MarshalText() ([]byte, error) {
+	return []byte(formatUnits(int64(qq), siUnits, "")), nil
+}
+
+func (pc *Percent) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	scale := 1.0
+	if strings.HasSuffix(s, "%") {
+		s, scale = strings.TrimSpace(s[:len(s)-1]), 100
+	}
+	fnum, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return numError(err)
+	}
+	*pc = Percent(fnum / scale)
+	return nil
+}
+
+func (pc Percent) MarshalText() ([]byte, error) {
+	for digits := 1; digits <= 17; digits++ { // the fewest digits that parse back, hiding the rounding of *100
+		s := strconv.FormatFloat(float64(pc)*100, 'g', digits, 64)
+		if fnum, err := strconv.ParseFloat(s, 64); err == nil && Percent(fnum/100) == pc {
+			return []byte(s + "%"), nil
+		}
+	}
+	return []byte(strconv.FormatFloat(float64(pc), 'g', -1, 64)), nil // a fraction, when no percentage parses back
+}
+
+// parseUnits parses a decimal number followed by one of units (case-insensitive) or no unit, as an integer.
+func parseUnits(s string, units []unit) (int64, error) {
+	s = strings.TrimSpace(s)
+	end := strings.IndexFunc(s, func(rr rune) bool { return !strings.ContainsRune("+-.0123456789", rr) })
+	if end < 0 {
+		end = len(s)
+	}
+	mult := 1.0
+	if suffix := strings.TrimSpace(s[end:]); suffix != "" {
+		mult = 0
+		for _, uu := range units {
+			if strings.EqualFold(suffix, uu.name) {
+				mult = uu.mult
+				break
+			}
+		}
+		if mult == 0 {
+			return 0, errParse
+		}
+	}
+	if inum, err := strconv.ParseInt(s[:end], 10, 64); err == nil && mult == 1 {
+		return inum, nil // exact beyond the 53 bits of a float64
+	}
+	fnum, err := strconv.ParseFloat(s[:end], 64)
+	if err != nil {
+		return 0, numError(err)
+	}
+	fnum = math.Round(fnum * mult)
+	if fnum >= math.MaxInt64 || fnum < math.MinInt64 {
+		return 0, errRange
+	}
+	return int64(fnum), nil
+}
+
+// formatUnits renders num with the largest of units in which it takes at most three decimals, or with plain as unit.
+func formatUnits(num int64, units []unit, plain string) string {
+	for _, uu := range units {
+		scaled := float64(num) / uu.mult
+		if math.Abs(scaled) >= 1 && scaled*1000 == math.Trunc(scaled*1000) && int64(scaled*uu.mult) == num {
+			return strconv.FormatFloat(scaled, 'f', -1, 64) + uu.name
+		}
+	}
+	return strconv.FormatInt(num, 10) + plain
+}
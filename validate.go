@@ -0,0 +1,128 @@
+package sflag
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// validate checks the parsed values against the required, min, max and enum options of their tags.
+func (p *Parser) validate() error {
+	for _, flagname := range p.order {
+		ff := p.fields[flagname]
+		vv := p.target(ff, false)
+		if _, ok := ff.opts["required"]; ok {
+			if src := p.sources[flagname]; src != SourceCommandLine && src != SourceFile && src != SourcePrompt {
+				return fmt.Errorf("flag -%s is required", flagname)
+			}
+		}
+		if !vv.IsValid() {
+			continue
+		}
+		if vv.Kind() == reflect.Ptr {
+			if vv.IsNil() {
+				continue
+			}
+			vv = vv.Elem()
+		}
+
+		shown := formatValue(vv)
+		if ff.secret() {
+			shown = redacted
+		}
+		for _, bound := range []string{"min", "max"} {
+			limit, ok := ff.opts[bound]
+			if !ok {
+				continue
+			}
+			if !isNumber(vv.Type()) {
+				return fmt.Errorf("sflag: %s option on non-numeric member %s", bound, ff.name)
+			}
+			lnum, err := limitNumber(vv.Type(), limit)
+			if err != nil {
+				return fmt.Errorf("sflag: bad %s=%s option on member %s", bound, limit, ff.name)
+			}
+			if num := numberOf(vv); (bound == "min" && num < lnum) || (bound == "max" && num > lnum) {
+				return fmt.Errorf("invalid value %s for flag -%s: %s is %s", shown, flagname, bound, limit)
+			}
+		}
+		if choices := ff.enum(); choices != nil {
+			elems := []reflect.Value{vv}
+			if isList(vv.Type()) { // the choices apply to each element
+				elems = elems[:0]
+				for ii := 0; ii < vv.Len(); ii++ {
+					elems = append(elems, vv.Index(ii))
+				}
+			}
+			for _, elem := range elems {
+				ok := false
+				for _, choice := range choices {
+					if choiceMatches(elem, choice) {
+						ok = true
+					}
+				}
+				if !ok {
+					hint := ""
+					if !ff.secret() {
+						hint = didYouMean(formatValue(elem), choices)
+					}
+					return fmt.Errorf("invalid value %s for flag -%s: must be one of %s%s", shown, flagname, strings.Join(choices, ", "), hint)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isNumber reports whether the min and max options apply to members of type typ.
+func isNumber(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// numberOf returns the value of vv, which must be of a numeric kind, as a float64.
+func numberOf(vv reflect.Value) float64 {
+	switch vv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(vv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(vv.Uint())
+	}
+	return vv.Float()
+}
+
+// limitNumber parses the value of a min or max option on a member of type typ, in the syntax of the member
+// for text and duration types, e.g. max=1GiB or min=1s.
+func limitNumber(typ reflect.Type, limit string) (float64, error) {
+	if isText(typ) || typ == durationType {
+		if lv, ok := parseDefault(field{typ: typ, def: limit}); ok {
+			return numberOf(lv), nil
+		}
+	}
+	return strconv.ParseFloat(limit, 64)
+}
+
+// enum returns the accepted values from the enum option, or nil if there is none.
+func (ff field) enum() []string {
+	list, ok := ff.opts["enum"]
+	if !ok {
+		return nil
+	}
+	choices := strings.Split(list, "/")
+	for ii := range choices {
+		choices[ii] = strings.TrimSpace(choices[ii])
+	}
+	return choices
+}
+
+// choiceMatches reports whether vv holds the value spelled by choice.
+func choiceMatches(vv reflect.Value, choice string) bool {
+	cv, ok := parseDefault(field{typ: vv.Type(), def: choice})
+	return ok && formatValue(cv) == formatValue(vv)
+}
@@ -0,0 +1,293 @@
+package sflag
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+	ipNetType           = reflect.TypeOf(net.IPNet{})
+	urlType             = reflect.TypeOf(url.URL{})
+
+	// as reported by the flag package
+	errParse = errors.New("parse error")
+	errRange = errors.New("value out of range")
+)
+
+// isText reports whether members of type typ are set from their text form: implementations of encoding.TextUnmarshaler,
+// and net.IPNet and url.URL, which lack the method.
+func isText(typ reflect.Type) bool {
+	return typ == ipNetType || typ == urlType || reflect.PtrTo(typ).Implements(textUnmarshalerType)
+}
+
+// scalarKind reports whether Parse sets a member of type typ from a single value.
+func scalarKind(typ reflect.Type) bool {
+	if isText(typ) || typ == durationType {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// supportedKind reports whether Parse binds a member of type typ to a flag: a scalar, a slice of scalars, or a pointer to either.
+func supportedKind(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return scalarKind(typ) || (typ.Kind() == reflect.Slice && scalarKind(typ.Elem()))
+}
+
+// nestedKind reports whether a member of type typ is a struct (or pointer to struct) whose members become flags of their own.
+func nestedKind(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct && !isText(typ)
+}
+
+// isInteger reports whether typ is an int or uint kind, other than time.Duration.
+func isInteger(typ reflect.Type) bool {
+	if typ == durationType || isText(typ) {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// isList reports whether typ is a slice that takes comma-separated values, rather than a TextUnmarshaler such as net.IP.
+func isList(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && !isText(typ)
+}
+
+// setValue parses the flag value s into vv, which must be settable and of a scalar or slice type.
+// Integers are parsed like the flag package does, so that 0x10 is 16 and 010 is 8.
+func setValue(vv reflect.Value, s string) error { return parseValue(vv, s, 0) }
+
+// setTagValue parses s from a tag (a default, limit or enum choice) into vv, like setValue but with decimal integers, so that 010 is 10.
+func setTagValue(vv reflect.Value, s string) error { return parseValue(vv, s, 10) }
+
+// parseValue parses s into vv, reading integers in base (0 for the prefix to choose it).
+// net.IPNet takes CIDR notation, and url.URL an absolute URL.  Slices take comma-separated values, and the empty string for an empty slice.
+func parseValue(vv reflect.Value, s string, base int) error {
+	typ := vv.Type()
+	if list := enumValues(typ); list != nil {
+		return setEnum(vv, list, s)
+	}
+	switch {
+	case (typ == ipNetType || typ == urlType) && s == "":
+		vv.Set(reflect.Zero(typ))
+		return nil
+	case typ == ipNetType:
+		_, ipnet, err := net.ParseCIDR(s)
+		if err != nil {
+			return err
+		}
+		vv.Set(reflect.ValueOf(*ipnet))
+		return nil
+	case typ == urlType:
+		uu, err := url.Parse(s)
+		if err != nil {
+			return err
+		}
+		if uu.Scheme == "" || (uu.Host == "" && uu.Opaque == "" && uu.Path == "") {
+			return fmt.Errorf("%q is not an absolute URL", s)
+		}
+		vv.Set(reflect.ValueOf(*uu))
+		return nil
+	case isText(typ):
+		return vv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	case typ == durationType:
+		dur, err := time.ParseDuration(s)
+		if err != nil {
+			return errParse
+		}
+		vv.SetInt(int64(dur))
+		return nil
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		vv.SetString(s)
+	case reflect.Bool:
+		bnum, err := strconv.ParseBool(s)
+		if err != nil {
+			return errParse
+		}
+		vv.SetBool(bnum)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		inum, err := strconv.ParseInt(s, base, typ.Bits())
+		if err != nil {
+			return numError(err)
+		}
+		vv.SetInt(inum)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		unum, err := strconv.ParseUint(s, base, typ.Bits())
+		if err != nil {
+			return numError(err)
+		}
+		vv.SetUint(unum)
+	case reflect.Float32, reflect.Float64:
+		fnum, err := strconv.ParseFloat(s, typ.Bits())
+		if err != nil {
+			return numError(err)
+		}
+		vv.SetFloat(fnum)
+	case reflect.Slice:
+		list := reflect.MakeSlice(typ, 0, 0)
+		if s != "" {
+			parts, err := splitList(s)
+			if err != nil {
+				return err
+			}
+			for _, part := range parts {
+				elem := reflect.New(typ.Elem()).Elem()
+				if err := parseValue(elem, part, base); err != nil {
+					return err
+				}
+				list = reflect.Append(list, elem)
+			}
+		}
+		vv.Set(list)
+	default:
+		return fmt.Errorf("unsupported type %s", typ)
+	}
+	return nil
+}
+
+// splitList splits the value of a slice member at its commas, trimming the spaces around each element.
+// An element in double quotes, as in "a, b",c, is unquoted like a Go string instead, keeping its commas and spaces.
+func splitList(s string) ([]string, error) {
+	var parts []string
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		var part string
+		if strings.HasPrefix(s, `"`) {
+			quoted, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil, errParse
+			}
+			part, _ = strconv.Unquote(quoted)
+			s = strings.TrimLeftFunc(s[len(quoted):], unicode.IsSpace)
+			if s != "" && s[0] != ',' {
+				return nil, errParse
+			}
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			part, s = strings.TrimSpace(s[:end]), s[end:]
+		}
+		parts = append(parts, part)
+		if s == "" {
+			return parts, nil
+		}
+		s = s[1:] // the comma
+	}
+}
+
+// quoteElem quotes an element of a slice value if splitList would not give it back as is.
+func quoteElem(elem string) string {
+	if elem == "" || strings.ContainsRune(elem, ',') || strings.HasPrefix(elem, `"`) || strings.TrimSpace(elem) != elem {
+		return strconv.Quote(elem)
+	}
+	return elem
+}
+
+func numError(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+		return errRange
+	}
+	return errParse
+}
+
+// formatValue renders vv (dereferenced if it is a pointer) the way setValue parses it back.
+func formatValue(vv reflect.Value) string {
+	if vv.Kind() == reflect.Ptr {
+		if vv.IsNil() {
+			return ""
+		}
+		vv = vv.Elem()
+	}
+	typ := vv.Type()
+	for _, ev := range enumValues(typ) {
+		if ev.value.Interface() == vv.Interface() {
+			return ev.name
+		}
+	}
+	switch {
+	case (typ == ipNetType || typ == urlType) && vv.IsZero():
+		return ""
+	case typ == ipNetType:
+		ipnet := vv.Interface().(net.IPNet)
+		return ipnet.String()
+	case typ == urlType:
+		uu := vv.Interface().(url.URL)
+		return uu.String()
+	case typ.Implements(textMarshalerType):
+		buf, _ := vv.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(buf)
+	case reflect.PtrTo(typ).Implements(textMarshalerType) && vv.CanAddr():
+		buf, _ := vv.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(buf)
+	case typ == durationType:
+		return time.Duration(vv.Int()).String()
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return vv.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(vv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(vv.Uint(), 10)
+	case reflect.Bool:
+		return strconv.FormatBool(vv.Bool())
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(vv.Float(), 'g', -1, typ.Bits())
+	case reflect.Slice:
+		parts := make([]string, vv.Len())
+		for ii := range parts {
+			parts[ii] = quoteElem(formatValue(vv.Index(ii)))
+		}
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprint(vv.Interface())
+}
+
+// lookup returns the member at index below root, walking through nested structs.
+// It returns the invalid Value if the walk meets a nil pointer to a nested struct.
+func lookup(root reflect.Value, index []int) reflect.Value {
+	vv := root
+	for _, ii := range index {
+		if vv.Kind() == reflect.Ptr {
+			if vv.IsNil() {
+				return reflect.Value{}
+			}
+			vv = vv.Elem()
+		}
+		vv = vv.Field(ii)
+	}
+	return vv
+}